@@ -9,13 +9,35 @@
 // "/assets/dexetera/widgets/growth/main.wasm". The defaults below leave
 // them empty — the codegen falls back to "./runtime/" + "./src/main.wasm",
 // which keeps the local-preview test.html happy.
+//
+// With -strict-csp the style and script are written as separate files
+// with integrity hashes, and the Content-Security-Policy the page needs
+// is printed. With -web-component the snippet is a <dexetera-widget>
+// custom element loading growth.json. With -poster each canvas shows a
+// static image of the simulation until the wasm starts.
 package main
 
 import (
+	"flag"
+	"fmt"
+
 	"github.com/umbralcalc/dexetera/pkg/dashboard"
 	"github.com/umbralcalc/dexetera/pkg/growth"
 )
 
 func main() {
-	dashboard.MustGenerateWidget(growth.NewConfig(), dashboard.WidgetOptions{})
+	strictCSP := flag.Bool("strict-csp", false, "emit CSP-compatible output with SRI hashes")
+	webComponent := flag.Bool("web-component", false, "emit a <dexetera-widget> custom element and its JSON spec")
+	poster := flag.Bool("poster", false, "show a static poster image until the simulation starts")
+	flag.Parse()
+
+	cfg := growth.NewConfig()
+	opts := dashboard.WidgetOptions{StrictCSP: *strictCSP, WebComponent: *webComponent}
+	if *poster {
+		opts.Poster = &dashboard.PosterOptions{}
+	}
+	dashboard.MustGenerateWidget(cfg, opts)
+	if *strictCSP {
+		fmt.Println("Content-Security-Policy:", dashboard.RecommendedCSP(cfg, opts))
+	}
 }
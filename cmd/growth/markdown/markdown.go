@@ -0,0 +1,40 @@
+// markdown expands ```dexetera name=growth``` directives in a directory
+// of Markdown posts into the growth widget, writing the results to
+// another directory:
+//
+//	go run ./cmd/growth/markdown -in posts -out _posts -runtime-url /assets/dexetera/runtime/ -widgets-url /assets/dexetera/widgets/
+//
+// Register your own Configs alongside growth's to expand their
+// directives too.
+package main
+
+import (
+	"flag"
+	"log"
+
+	"github.com/umbralcalc/dexetera/pkg/growth"
+	"github.com/umbralcalc/dexetera/pkg/markdown"
+)
+
+func main() {
+	in := flag.String("in", "", "directory of Markdown files to expand")
+	out := flag.String("out", "", "directory to write the expanded files to (may equal -in)")
+	runtimeURL := flag.String("runtime-url", "", "URL the site serves the runtime at")
+	widgetsURL := flag.String("widgets-url", "", "URL the site serves each widget's wasm and images under")
+	flag.Parse()
+	if *in == "" || *out == "" {
+		log.Fatal("markdown: -in and -out are required")
+	}
+
+	reg, err := markdown.NewRegistry(growth.NewConfig())
+	if err != nil {
+		log.Fatalf("markdown: %v", err)
+	}
+	err = markdown.ExpandDir(*in, *out, reg, markdown.Options{
+		RuntimeBaseURL: *runtimeURL,
+		WidgetsBaseURL: *widgetsURL,
+	})
+	if err != nil {
+		log.Fatalf("markdown: %v", err)
+	}
+}
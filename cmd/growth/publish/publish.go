@@ -0,0 +1,40 @@
+// publish copies the growth example's runtime, wasm and widget into a
+// static site directory under content-hashed filenames, then regenerates
+// growth/widget.html to load them. Build the wasm first:
+//
+//	./growth/build.sh
+//	go run ./cmd/growth/publish -static ../blog/assets/dexetera -base-url /assets/dexetera/
+//
+// The asset manifest is written to <static>/widgets/growth/manifest.json.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"maps"
+	"slices"
+
+	"github.com/umbralcalc/dexetera/pkg/dashboard"
+	"github.com/umbralcalc/dexetera/pkg/growth"
+)
+
+func main() {
+	static := flag.String("static", "", "static site directory to publish into")
+	baseURL := flag.String("base-url", "/", "URL the static directory is served at")
+	flag.Parse()
+	if *static == "" {
+		log.Fatal("publish: -static is required")
+	}
+
+	manifest, err := dashboard.PublishAssets(growth.NewConfig(), dashboard.PublishOptions{
+		StaticDir: *static,
+		BaseURL:   *baseURL,
+	})
+	if err != nil {
+		log.Fatalf("publish: %v", err)
+	}
+	for _, name := range slices.Sorted(maps.Keys(manifest)) {
+		fmt.Printf("%-28s %s\n", name, manifest[name])
+	}
+}
@@ -0,0 +1,32 @@
+// site writes the growth example's Hugo shortcode, Jekyll include and
+// MkDocs snippet, so that a post embeds the widget by name:
+//
+//	go run ./cmd/growth/site -out ../blog/dexetera -runtime-url /assets/dexetera/runtime/ -widgets-url /assets/dexetera/widgets/
+//
+// Then write {{< dexetera "growth" >}} in a Hugo post, or
+// {% include dexetera.html name="growth" %} in a Jekyll one.
+package main
+
+import (
+	"flag"
+	"log"
+
+	"github.com/umbralcalc/dexetera/pkg/dashboard"
+	"github.com/umbralcalc/dexetera/pkg/growth"
+)
+
+func main() {
+	out := flag.String("out", "site/", "directory to write the hugo/, jekyll/ and mkdocs/ trees into")
+	runtimeURL := flag.String("runtime-url", "", "URL the site serves the runtime at")
+	widgetsURL := flag.String("widgets-url", "", "URL the site serves each widget's wasm and images under")
+	flag.Parse()
+
+	err := dashboard.GenerateSiteIntegrations([]*dashboard.Config{growth.NewConfig()}, dashboard.SiteOptions{
+		OutputDir:      *out,
+		RuntimeBaseURL: *runtimeURL,
+		WidgetsBaseURL: *widgetsURL,
+	})
+	if err != nil {
+		log.Fatalf("site: %v", err)
+	}
+}
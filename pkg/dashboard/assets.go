@@ -0,0 +1,92 @@
+package dashboard
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// isAssetURL reports whether an image path is a URL the widget should
+// load as is, rather than a local file for GenerateWidget to copy.
+func isAssetURL(path string) bool {
+	return strings.Contains(path, "://") ||
+		strings.HasPrefix(path, "/") ||
+		strings.HasPrefix(path, "data:") ||
+		strings.HasPrefix(path, "blob:")
+}
+
+// localAssets returns the local file paths referenced by image renderers
+// on every canvas, deduplicated and sorted.
+func (cfg *Config) localAssets() []string {
+	vis := make([]*VisualizationConfig, 0, len(cfg.Canvases)+1)
+	if cfg.VisualizationConfig != nil {
+		vis = append(vis, cfg.VisualizationConfig)
+	}
+	for _, c := range cfg.Canvases {
+		if c.Visualization != nil {
+			vis = append(vis, c.Visualization)
+		}
+	}
+	seen := make(map[string]struct{})
+	for _, v := range vis {
+		for _, r := range v.Renderers {
+			if r.Type != "image" {
+				continue
+			}
+			path, _ := r.Properties["imagePath"].(string)
+			if path == "" || isAssetURL(path) {
+				continue
+			}
+			seen[path] = struct{}{}
+		}
+	}
+	return slices.Sorted(maps.Keys(seen))
+}
+
+// validateAssets rejects local image paths that would be copied from (and
+// to) somewhere outside the asset directories.
+func (cfg *Config) validateAssets() error {
+	for _, path := range cfg.localAssets() {
+		if !filepath.IsLocal(filepath.FromSlash(path)) {
+			return fmt.Errorf(
+				"image path %q must be a URL or a relative path that stays inside the asset directory", path)
+		}
+	}
+	return nil
+}
+
+// copyAssets copies every local image from srcDir into outDir/assets/,
+// keeping each file's relative path.
+func copyAssets(cfg *Config, srcDir, outDir string) error {
+	for _, path := range cfg.localAssets() {
+		rel := filepath.FromSlash(path)
+		if err := copyFile(filepath.Join(srcDir, rel), filepath.Join(outDir, "assets", rel)); err != nil {
+			return fmt.Errorf("failed to copy image %q: %w", path, err)
+		}
+	}
+	return nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,71 @@
+package dashboard_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/umbralcalc/dexetera/pkg/dashboard"
+)
+
+// withImages returns baseBuilder with a visualization drawing each path.
+func withImages(paths ...string) *dashboard.ConfigBuilder {
+	vb := dashboard.NewVisualizationBuilder()
+	for _, p := range paths {
+		vb.AddImage("", p, 0, 0, nil)
+	}
+	return baseBuilder().WithVisualization(vb.Build())
+}
+
+func TestValidate_Assets(t *testing.T) {
+	t.Run("relative paths and URLs pass", func(t *testing.T) {
+		cfg := withImages("sprites/ship.png", "https://example.com/a.png", "/static/b.png", "data:image/png;base64,AA==").Build()
+		if err := cfg.Validate(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("path escaping the asset directory is rejected", func(t *testing.T) {
+		cfg := withImages("../secret.png").Build()
+		expectError(t, cfg.Validate(), `image path "../secret.png"`)
+	})
+}
+
+func TestGenerateWidget_Assets(t *testing.T) {
+	src := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(src, "sprites"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(src, "sprites", "ship.png"), []byte("png"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := withImages("sprites/ship.png", "https://example.com/a.png").Build()
+
+	dir := t.TempDir()
+	err := dashboard.GenerateWidget(cfg, dashboard.WidgetOptions{
+		OutputDir:      dir,
+		AssetSourceDir: src,
+		AssetBaseURL:   "/assets/dexetera/widgets/test/assets/",
+	})
+	if err != nil {
+		t.Fatalf("GenerateWidget: %v", err)
+	}
+	copied, err := os.ReadFile(filepath.Join(dir, "assets", "sprites", "ship.png"))
+	if err != nil || string(copied) != "png" {
+		t.Fatalf("expected the image copied into assets/, got %q (%v)", copied, err)
+	}
+	raw, err := os.ReadFile(filepath.Join(dir, "widget.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(raw), `var ASSET_BASE = '/assets/dexetera/widgets/test/assets/';`) {
+		t.Errorf("widget.html does not use the configured asset base")
+	}
+
+	t.Run("missing file is reported", func(t *testing.T) {
+		cfg := withImages("missing.png").Build()
+		err := dashboard.GenerateWidget(cfg, dashboard.WidgetOptions{OutputDir: t.TempDir(), AssetSourceDir: src})
+		expectError(t, err, `failed to copy image "missing.png"`)
+	})
+}
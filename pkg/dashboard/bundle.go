@@ -0,0 +1,121 @@
+package dashboard
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// workerScripts are the runtime files worker.js imports at load, in order.
+var workerScripts = []string{
+	"wasm_exec.js",
+	"google-protobuf.js",
+	"partition_state_pb.js",
+	"action_state_pb.js",
+}
+
+// widgetBundle is what a single-file widget inlines in place of the
+// runtime and wasm URLs.
+type widgetBundle struct {
+	// RendererSource is renderer.js, run in place of loading it by URL.
+	RendererSource string
+	// WorkerSourceJSON is the worker's whole script (imports, driver and
+	// worker.js) as a JS string literal, started from a Blob URL.
+	WorkerSourceJSON string
+	// WasmDataURL is the gzipped wasm binary as a base64 data: URL.
+	WasmDataURL string
+	// AssetsJSON maps each local image path to a data: URL of the file.
+	AssetsJSON string
+}
+
+// loadBundle reads the runtime files from opts.RuntimeDir, the built wasm
+// from opts.BundleWasmPath and any local images, ready for inlining into
+// the widget.
+func loadBundle(cfg *Config, opts WidgetOptions) (*widgetBundle, error) {
+	read := func(name string) (string, error) {
+		raw, err := os.ReadFile(filepath.Join(opts.RuntimeDir, filepath.FromSlash(name)))
+		if err != nil {
+			return "", fmt.Errorf("failed to read runtime file: %w", err)
+		}
+		// Inlined inside a <script> element, the source must not close it.
+		if strings.Contains(strings.ToLower(string(raw)), "</script") {
+			return "", fmt.Errorf("runtime file %s contains </script and cannot be inlined", name)
+		}
+		return string(raw), nil
+	}
+
+	renderer, err := read("renderer.js")
+	if err != nil {
+		return nil, err
+	}
+
+	kind := cfg.Driver.Kind
+	if kind == "" {
+		kind = "websocket"
+	}
+	inlined := append(append([]string{}, workerScripts...), "drivers/"+kind+".js")
+	names, err := json.Marshal(inlined)
+	if err != nil {
+		return nil, err
+	}
+	var worker strings.Builder
+	fmt.Fprintf(&worker, "self.dexeteraInlined = %s;\n", names)
+	for _, name := range append(inlined, "worker.js") {
+		src, err := read(name)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(&worker, "// --- %s ---\n%s\n;\n", name, src)
+	}
+	// json.Marshal escapes <, > and &, so the literal is safe in <script>.
+	workerJSON, err := json.Marshal(worker.String())
+	if err != nil {
+		return nil, err
+	}
+
+	wasm, err := os.ReadFile(opts.BundleWasmPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read wasm for bundling (run build.sh first): %w", err)
+	}
+	var gz bytes.Buffer
+	zw, err := gzip.NewWriterLevel(&gz, gzip.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := zw.Write(wasm); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	assets := make(map[string]string)
+	for _, path := range cfg.localAssets() {
+		raw, err := os.ReadFile(filepath.Join(opts.AssetSourceDir, filepath.FromSlash(path)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read image %q for bundling: %w", path, err)
+		}
+		kind := mime.TypeByExtension(filepath.Ext(path))
+		if kind == "" {
+			kind = "application/octet-stream"
+		}
+		assets[path] = "data:" + kind + ";base64," + base64.StdEncoding.EncodeToString(raw)
+	}
+	assetsJSON, err := json.Marshal(assets)
+	if err != nil {
+		return nil, err
+	}
+
+	return &widgetBundle{
+		RendererSource:   renderer,
+		WorkerSourceJSON: string(workerJSON),
+		WasmDataURL:      "data:application/gzip;base64," + base64.StdEncoding.EncodeToString(gz.Bytes()),
+		AssetsJSON:       string(assetsJSON),
+	}, nil
+}
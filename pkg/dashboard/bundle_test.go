@@ -0,0 +1,73 @@
+package dashboard_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/umbralcalc/dexetera/pkg/dashboard"
+)
+
+func TestGenerateWidget_Bundle(t *testing.T) {
+	wasm := filepath.Join(t.TempDir(), "main.wasm")
+	if err := os.WriteFile(wasm, []byte("\x00asm fake"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	err := dashboard.GenerateWidget(baseBuilder().Build(), dashboard.WidgetOptions{
+		OutputDir:      dir,
+		Bundle:         true,
+		RuntimeDir:     "../../runtime",
+		BundleWasmPath: wasm,
+	})
+	if err != nil {
+		t.Fatalf("GenerateWidget: %v", err)
+	}
+	raw, err := os.ReadFile(filepath.Join(dir, "widget.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	html := string(raw)
+	for _, want := range []string{
+		"self.dexetera.createRenderer = function",
+		"var WASM_GZIP = true;",
+		`self.dexeteraInlined = [\"wasm_exec.js\"`,
+		`// --- drivers/inline.js ---`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("bundled widget.html is missing %q", want)
+		}
+	}
+
+	m := regexp.MustCompile(`var WASM_URL = 'data:application/gzip;base64,([^']*)';`).FindStringSubmatch(html)
+	if m == nil {
+		t.Fatal("bundled widget.html has no inline wasm")
+	}
+	gz, err := base64.StdEncoding.DecodeString(m[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(gz))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := io.ReadAll(zr)
+	if err != nil || string(got) != "\x00asm fake" {
+		t.Errorf("inline wasm decodes to %q (%v)", got, err)
+	}
+
+	t.Run("missing wasm is reported", func(t *testing.T) {
+		err := dashboard.GenerateWidget(baseBuilder().Build(), dashboard.WidgetOptions{
+			OutputDir:  t.TempDir(),
+			Bundle:     true,
+			RuntimeDir: "../../runtime",
+		})
+		expectError(t, err, "run build.sh first")
+	})
+}
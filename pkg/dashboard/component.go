@@ -0,0 +1,85 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"html"
+	"text/template"
+)
+
+// componentScriptName is the custom element definition shared by every
+// WebComponent widget.
+const componentScriptName = "dexetera-widget.js"
+
+// componentSpec is the JSON a <dexetera-widget> loads from its config
+// attribute: the widget's style and markup, where to find the runtime,
+// and the game config.
+type componentSpec struct {
+	Style       string          `json:"style"`
+	Markup      string          `json:"markup"`
+	RendererURL string          `json:"rendererURL"`
+	WorkerURL   string          `json:"workerURL"`
+	WasmURL     string          `json:"wasmURL"`
+	AssetBase   string          `json:"assetBase"`
+	Assets      json.RawMessage `json:"assets"`
+	GameConfig  json.RawMessage `json:"gameConfig"`
+}
+
+// writeComponent writes the custom element definition and cfg's spec
+// (as specName) into opts.OutputDir, and returns the usage snippet that
+// loads them from opts.WidgetBaseURL.
+//
+// Inside the element's shadow root the style rules hang off ":host"
+// rather than "#<WidgetID>", so the page's styles and the widget's can't
+// reach each other.
+func writeComponent(cfg *Config, opts WidgetOptions, specName string) (string, error) {
+	data, err := newWidgetData(cfg, opts)
+	if err != nil {
+		return "", err
+	}
+	data.Scope = ":host"
+
+	tmpl, err := template.New("widget").Parse(widgetTemplate)
+	if err != nil {
+		return "", err
+	}
+	execute := func(name string) (string, error) {
+		var buf stringBuilder
+		err := tmpl.ExecuteTemplate(&buf, name, data)
+		return buf.String(), err
+	}
+
+	spec := componentSpec{
+		RendererURL: data.RendererURL,
+		WorkerURL:   data.WorkerURL,
+		WasmURL:     data.WasmURL,
+		AssetBase:   data.AssetBase,
+		Assets:      json.RawMessage(data.AssetsJSON),
+		GameConfig:  json.RawMessage(data.GameConfigJSON),
+	}
+	if spec.Style, err = execute("style"); err != nil {
+		return "", err
+	}
+	if spec.Markup, err = execute("markup"); err != nil {
+		return "", err
+	}
+	raw, err := json.MarshalIndent(spec, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if err := writeFile(opts.OutputDir, specName, string(raw)+"\n"); err != nil {
+		return "", err
+	}
+
+	script, err := execute("component")
+	if err != nil {
+		return "", err
+	}
+	if err := writeFile(opts.OutputDir, componentScriptName, script); err != nil {
+		return "", err
+	}
+
+	return `<script src="` + html.EscapeString(opts.WidgetBaseURL+componentScriptName) + `"></script>
+<dexetera-widget id="` + html.EscapeString(opts.WidgetID) + `" config="` +
+		html.EscapeString(opts.WidgetBaseURL+specName) + `"></dexetera-widget>
+`, nil
+}
@@ -0,0 +1,71 @@
+package dashboard_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/umbralcalc/dexetera/pkg/dashboard"
+)
+
+func TestGenerateWidget_WebComponent(t *testing.T) {
+	dir := t.TempDir()
+	opts := dashboard.WidgetOptions{
+		OutputDir:      dir,
+		WebComponent:   true,
+		WidgetBaseURL:  "/widgets/test/",
+		RuntimeBaseURL: "/runtime/",
+	}
+	if err := dashboard.GenerateWidget(baseBuilder().Build(), opts); err != nil {
+		t.Fatalf("GenerateWidget: %v", err)
+	}
+	read := func(name string) string {
+		t.Helper()
+		raw, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(raw)
+	}
+
+	want := `<script src="/widgets/test/dexetera-widget.js"></script>
+<dexetera-widget id="dexetera-test" config="/widgets/test/test.json"></dexetera-widget>
+`
+	if got := read("widget.html"); got != want {
+		t.Errorf("widget.html:\n got %q\nwant %q", got, want)
+	}
+	if !strings.Contains(read("test.html"), `config="./test.test.json"`) {
+		t.Errorf("test.html should load the local spec")
+	}
+	if !strings.Contains(read("dexetera-widget.js"), "customElements.define('dexetera-widget'") {
+		t.Errorf("dexetera-widget.js does not define the custom element")
+	}
+
+	var spec struct {
+		Style       string          `json:"style"`
+		Markup      string          `json:"markup"`
+		RendererURL string          `json:"rendererURL"`
+		GameConfig  json.RawMessage `json:"gameConfig"`
+	}
+	if err := json.Unmarshal([]byte(read("test.json")), &spec); err != nil {
+		t.Fatalf("test.json: %v", err)
+	}
+	if !strings.HasPrefix(spec.Style, ":host {") || strings.Contains(spec.Style, "#dexetera-test") {
+		t.Errorf("spec style should be scoped to :host, got:\n%s", spec.Style)
+	}
+	if !strings.Contains(spec.Markup, `data-status`) || strings.Contains(spec.Markup, "<script") {
+		t.Errorf("spec markup should be the widget's markup alone, got:\n%s", spec.Markup)
+	}
+	if spec.RendererURL != "/runtime/renderer.js" || len(spec.GameConfig) == 0 {
+		t.Errorf("spec is missing its runtime URL or game config: %+v", spec)
+	}
+
+	t.Run("cannot be combined with StrictCSP", func(t *testing.T) {
+		err := dashboard.GenerateWidget(baseBuilder().Build(), dashboard.WidgetOptions{
+			OutputDir: t.TempDir(), WebComponent: true, StrictCSP: true,
+		})
+		expectError(t, err, "WebComponent cannot be combined")
+	})
+}
@@ -11,10 +11,15 @@
 // The two builders, ConfigBuilder and VisualizationBuilder, exist so that
 // example simulations can describe themselves declaratively. Neither
 // performs validation; both are just typed convenience wrappers over the
-// underlying config structs.
+// underlying config structs. Cross-references between fields (e.g. preset
+// values naming sliders) are checked by Config.Validate, which
+// GenerateWidget calls before emitting anything.
 package dashboard
 
 import (
+	"math"
+	"sort"
+
 	"github.com/umbralcalc/stochadex/pkg/simulator"
 )
 
@@ -59,6 +64,12 @@ type Config struct {
 	// partition's state.
 	VisualizationConfig *VisualizationConfig
 
+	// Canvases are further visualizations drawn alongside the primary one,
+	// each on its own <canvas> with its own size — e.g. a spatial view next
+	// to a time-series chart. Every partition state is fed to every canvas.
+	// VisualizationConfig may be nil when at least one Canvas is set.
+	Canvases []NamedCanvas
+
 	// SimulationGenerator is invoked once at startup to obtain a fresh
 	// stochadex ConfigGenerator. The runtime then replaces its OutputCondition
 	// and OutputFunction with the wasm-side equivalents and wires action
@@ -70,16 +81,40 @@ type Config struct {
 	// slot in the inline driver's outgoing action vector.
 	Sliders []Slider
 
+	// Toggles declare checkboxes that write 1 (checked) or 0 into one
+	// action slot, typically to switch a simulation mode on or off.
+	// Sliders and toggles share one namespace of control names.
+	Toggles []Toggle
+
 	// Readouts declare DOM text elements the codegen should emit into the
 	// chart panel(s). Each readout subscribes to one partition's state and
 	// formats it via a small template (see Readout.Template).
 	Readouts []Readout
 
+	// Presets declare named bundles of control values that the codegen
+	// renders as buttons in the controls panel. Clicking one moves every
+	// listed control to its preset value (see Preset).
+	Presets []Preset
+
+	// Links declare prose links that set controls on this widget from
+	// outside it. GenerateWidget validates them and writes ready-to-paste
+	// markup for each into links.html (see ControlLink).
+	Links []ControlLink
+
+	// Scenarios declare scripted timelines of slider changes that the
+	// wasm runtime applies step by step, each rendered as a "play" button
+	// in the controls panel (see Scenario).
+	Scenarios []Scenario
+
 	// ShowReset toggles a "Reset simulation" button in the controls panel
 	// that re-launches the worker on click. Useful for dashboards where
 	// the user wants to restart the simulation without reloading the page.
 	ShowReset bool
 
+	// Layout arranges the canvas, readouts and controls into panels and
+	// groups. Nil selects the default two-panel layout (see Layout).
+	Layout *Layout
+
 	// Driver selects which action driver runtime/worker.js loads and what
 	// options to pass it. Build() fills in a sensible default if unset.
 	Driver DriverSpec
@@ -113,6 +148,53 @@ type Slider struct {
 	// Decimals is the number of fractional digits shown in the on-page
 	// readout. Defaults to 3 when zero.
 	Decimals int
+
+	// VisibleWhen and EnabledWhen make the slider conditional on another
+	// control's value (see Condition). Nil means always.
+	VisibleWhen, EnabledWhen *Condition
+}
+
+// Toggle declares a checkbox that drives one slot of one action
+// partition's `action_state_values` vector: 1 when checked, 0 when not.
+// It is published alongside the sliders, so a toggle and a slider may
+// share a Partition with distinct ValueIndex.
+type Toggle struct {
+	// Name is a unique slug, shared with slider names, used to refer to
+	// the toggle from presets, links, scenarios and conditions.
+	Name string
+
+	// Label is the human-readable string displayed alongside the checkbox.
+	Label string
+
+	Partition  string
+	ValueIndex int
+	Default    bool
+
+	VisibleWhen, EnabledWhen *Condition
+}
+
+// Condition makes a control's visibility or enabled state depend on the
+// live value of another control (a Slider or Toggle, by name). Op is one
+// of "==", "!=", "<", "<=", ">", ">="; empty means "==". A toggle's value
+// is 1 when checked and 0 otherwise, so WhenOn covers the common case.
+//
+// Conditions are re-evaluated in the widget whenever any control changes.
+// Hidden or disabled controls keep publishing their current value, so the
+// simulation always sees a complete action vector.
+type Condition struct {
+	Control string
+	Op      string
+	Value   float64
+}
+
+// When returns a Condition comparing control's value against value.
+func When(control, op string, value float64) *Condition {
+	return &Condition{Control: control, Op: op, Value: value}
+}
+
+// WhenOn returns a Condition that holds while the named toggle is checked.
+func WhenOn(toggle string) *Condition {
+	return &Condition{Control: toggle, Op: "==", Value: 1}
 }
 
 // Readout declares a DOM text element that displays values from one
@@ -133,6 +215,79 @@ type Readout struct {
 	Decimals int
 }
 
+// Preset is a named set of control values, so that prose around a widget
+// can refer to e.g. "the 'overshoot' scenario" and the reader can jump to
+// it with one click. Name labels its button and may not contain quotes,
+// backslashes, <, >, & or control characters. Values is keyed by control
+// (Slider or Toggle) name; controls not listed keep their current value.
+// A nonzero value checks a toggle.
+//
+// When Reset is true, applying the preset also restarts the simulation
+// from its initial state (as the Reset button would), which is usually
+// what you want when the preset is meant to show a transient.
+type Preset struct {
+	Name   string
+	Values map[string]float64
+	Reset  bool
+
+	VisibleWhen, EnabledWhen *Condition
+}
+
+// ControlLink is a link in the prose surrounding a widget that moves one
+// or more of its controls when clicked, e.g. "try r = 0.1". The widget
+// script finds such links by the data-attribute convention
+//
+//	<a data-dexetera-target="<widget id>" data-set-<control>="<value>" [data-reset]>
+//
+// which works for hand-written links too; declaring them here as well
+// means their control names are checked at generate time and their markup
+// is emitted for copy-pasting. HTML lowercases attribute names, so
+// control names are matched case-insensitively.
+type ControlLink struct {
+	Text   string
+	Values map[string]float64
+	Reset  bool
+}
+
+// Scenario is a guided demonstration: a timeline of control changes that
+// pkg/simio applies to the action partitions just before each coordinator
+// step, e.g. "for the first 200 steps r=0.05, then jump to 0.15, then K
+// drops". Manual controls are disabled in the widget while a scenario
+// plays, and they move to follow the timeline.
+// Name labels its play button, under the same restrictions as a Preset's.
+//
+// Event times are measured from the moment playback starts: in steps by
+// default, or in simulation time when ByTime is set. Playback ends once
+// the last event has been applied and Length (in the same unit) has
+// elapsed. When Reset is true, playing the scenario first restarts the
+// simulation from its initial state.
+type Scenario struct {
+	Name   string
+	ByTime bool
+	Length float64
+	Reset  bool
+	Events []ScenarioEvent
+
+	VisibleWhen, EnabledWhen *Condition
+}
+
+// ScenarioEvent sets the controls named in Values once At steps (or units
+// of simulation time) have elapsed since playback started.
+type ScenarioEvent struct {
+	At     float64
+	Values map[string]float64
+}
+
+// SortedEvents returns the scenario's events in playback order. Events
+// with equal At keep their declaration order. The wasm runtime and the
+// widget script both index events by this order.
+func (s Scenario) SortedEvents() []ScenarioEvent {
+	events := make([]ScenarioEvent, len(s.Events))
+	copy(events, s.Events)
+	sort.SliceStable(events, func(i, j int) bool { return events[i].At < events[j].At })
+	return events
+}
+
 // DriverSpec selects which file under runtime/drivers/ the worker loads
 // at startup. Kind is the driver name (e.g. "inline", "websocket");
 // Options is forwarded verbatim to createDriver as a JS object — its
@@ -147,14 +302,53 @@ type DriverSpec struct {
 // draws the listed Renderers on each animation frame using the partition
 // states it has most recently received from the wasm module.
 type VisualizationConfig struct {
-	CanvasWidth      int
-	CanvasHeight     int
+	CanvasWidth  int
+	CanvasHeight int
+	// BackgroundColor is a CSS colour or a theme token such as ThemeCanvas.
 	BackgroundColor  string
 	UpdateIntervalMs int
 
+	// World, if set, is the coordinate system the shape renderers (text,
+	// circle, rectangle, rectangleSet, line, pointSet, image) work in:
+	// their positions and sizes, static or from state, are mapped from
+	// world units onto the canvas, so the same config draws correctly at
+	// any canvas size. Nil means canvas pixels. Charts and the other
+	// boxed renderers always take their box in pixels.
+	World *WorldCoordinates
+
 	// Renderers describes how to project named partition states onto the
 	// canvas. Rendering happens in list order; later entries draw on top.
 	Renderers []RendererConfig
+
+	// earlyBinds are the properties VisualizationBuilder.Bind was asked to
+	// drive before any renderer existed, which Validate reports.
+	earlyBinds []string
+}
+
+// WorldCoordinates maps the world rectangle [MinX, MaxX] × [MinY, MaxY]
+// onto the canvas.
+type WorldCoordinates struct {
+	MinX float64
+	MaxX float64
+	MinY float64
+	MaxY float64
+
+	// LockAspect keeps one world unit the same number of pixels along
+	// both axes, centring the world rectangle within the canvas instead
+	// of stretching it to fill.
+	LockAspect bool
+
+	// YUp puts MinY at the bottom of the canvas, so y increases upwards.
+	// Otherwise MinY is at the top, as in canvas pixels.
+	YUp bool
+}
+
+// NamedCanvas is a VisualizationConfig placed under a name, so that a
+// Layout can refer to it as LayoutCanvasNamed(Name). The default layout
+// gives it a panel of its own, titled with the name.
+type NamedCanvas struct {
+	Name          string
+	Visualization *VisualizationConfig
 }
 
 // RendererConfig is one drawing element on the canvas, bound to a single
@@ -174,6 +368,29 @@ type RendererConfig struct {
 	// colours, formatting). Marshalled into the generated JS as a plain
 	// JS object literal.
 	Properties map[string]interface{}
+
+	// Bindings overrides named properties with live state values on every
+	// frame, e.g. {"x": {Index: 2, Scale: 10}} moves a shape with the
+	// third state value. A bound property keeps its static value until the
+	// state it reads has arrived.
+	Bindings map[string]StateRef
+}
+
+// StateRef reads one partition state value for a property binding:
+// state[Index] * Scale + Offset, where a zero Scale means 1. Partition
+// defaults to the renderer's own.
+//
+// With Template set the property becomes that string with "{value}"
+// replaced by the value to Decimals places, which binds text or colours:
+// Template "rgba(224,102,102,{value})" with Decimals 2 drives a fill's
+// intensity.
+type StateRef struct {
+	Partition string
+	Index     int
+	Scale     float64
+	Offset    float64
+	Template  string
+	Decimals  int
 }
 
 // VisualizationBuilder is a small fluent helper for assembling a
@@ -184,15 +401,15 @@ type VisualizationBuilder struct {
 }
 
 // NewVisualizationBuilder returns a builder seeded with conservative
-// defaults (small dark canvas, 100 ms update interval). The defaults
-// matter only if the caller doesn't override them via WithCanvas /
-// WithBackground / WithUpdateInterval.
+// defaults (small canvas in the theme's canvas colour, 100 ms update
+// interval). The defaults matter only if the caller doesn't override them
+// via WithCanvas / WithBackground / WithUpdateInterval.
 func NewVisualizationBuilder() *VisualizationBuilder {
 	return &VisualizationBuilder{
 		config: &VisualizationConfig{
 			CanvasWidth:      400,
 			CanvasHeight:     200,
-			BackgroundColor:  "#2a2a2a",
+			BackgroundColor:  ThemeCanvas,
 			UpdateIntervalMs: 100,
 			Renderers:        make([]RendererConfig, 0),
 		},
@@ -210,15 +427,38 @@ func (vb *VisualizationBuilder) WithBackground(color string) *VisualizationBuild
 	return vb
 }
 
+// WithWorld declares the world coordinate system the shape renderers
+// map through. See VisualizationConfig.World.
+func (vb *VisualizationBuilder) WithWorld(world WorldCoordinates) *VisualizationBuilder {
+	vb.config.World = &world
+	return vb
+}
+
 func (vb *VisualizationBuilder) WithUpdateInterval(ms int) *VisualizationBuilder {
 	vb.config.UpdateIntervalMs = ms
 	return vb
 }
 
+// Bind drives property of the most recently added renderer from a state
+// value; see RendererConfig.Bindings. Calling it before any renderer has
+// been added is reported by Config.Validate.
+func (vb *VisualizationBuilder) Bind(property string, ref StateRef) *VisualizationBuilder {
+	if len(vb.config.Renderers) == 0 {
+		vb.config.earlyBinds = append(vb.config.earlyBinds, property)
+		return vb
+	}
+	r := &vb.config.Renderers[len(vb.config.Renderers)-1]
+	if r.Bindings == nil {
+		r.Bindings = make(map[string]StateRef)
+	}
+	r.Bindings[property] = ref
+	return vb
+}
+
 // AddText appends a text label. The optional template token "{value}" inside
 // `text` is replaced at render time by the floored first element of the
 // bound partition's state (typically used for score readouts).
-func (vb *VisualizationBuilder) AddText(partitionName string, text string, x, y int, options *TextOptions) *VisualizationBuilder {
+func (vb *VisualizationBuilder) AddText(partitionName string, text string, x, y float64, options *TextOptions) *VisualizationBuilder {
 	props := map[string]interface{}{
 		"text": text,
 		"x":    x,
@@ -246,7 +486,7 @@ func (vb *VisualizationBuilder) AddText(partitionName string, text string, x, y
 	return vb
 }
 
-func (vb *VisualizationBuilder) AddCircle(partitionName string, x, y, radius int, options *ShapeOptions) *VisualizationBuilder {
+func (vb *VisualizationBuilder) AddCircle(partitionName string, x, y, radius float64, options *ShapeOptions) *VisualizationBuilder {
 	props := map[string]interface{}{
 		"x":      x,
 		"y":      y,
@@ -277,7 +517,7 @@ func (vb *VisualizationBuilder) AddCircle(partitionName string, x, y, radius int
 // AddRectangle appends a static axis-aligned rectangle with fixed position
 // and size. For position/size driven by simulation state, use AddRectangleSet
 // instead.
-func (vb *VisualizationBuilder) AddRectangle(partitionName string, x, y, width, height int, options *ShapeOptions) *VisualizationBuilder {
+func (vb *VisualizationBuilder) AddRectangle(partitionName string, x, y, width, height float64, options *ShapeOptions) *VisualizationBuilder {
 	props := map[string]interface{}{
 		"x":      x,
 		"y":      y,
@@ -312,7 +552,7 @@ func (vb *VisualizationBuilder) AddRectangle(partitionName string, x, y, width,
 // can compact entire slots by emitting all-zeros. By default (x, y) is the
 // rectangle's centre; set options.Anchor = "topLeft" to use the top-left
 // instead.
-func (vb *VisualizationBuilder) AddRectangleSet(partitionName string, width, height int, options *ShapeOptions) *VisualizationBuilder {
+func (vb *VisualizationBuilder) AddRectangleSet(partitionName string, width, height float64, options *ShapeOptions) *VisualizationBuilder {
 	props := map[string]interface{}{
 		"defaultWidth":  width,
 		"defaultHeight": height,
@@ -342,7 +582,7 @@ func (vb *VisualizationBuilder) AddRectangleSet(partitionName string, width, hei
 	return vb
 }
 
-func (vb *VisualizationBuilder) AddLine(partitionName string, x1, y1, x2, y2 int, options *LineOptions) *VisualizationBuilder {
+func (vb *VisualizationBuilder) AddLine(partitionName string, x1, y1, x2, y2 float64, options *LineOptions) *VisualizationBuilder {
 	props := map[string]interface{}{
 		"x1": x1,
 		"y1": y1,
@@ -390,6 +630,7 @@ func (vb *VisualizationBuilder) AddBarChart(partitionName string, x, y, width, h
 		if options.LabelFormat != "" {
 			props["labelFormat"] = options.LabelFormat
 		}
+		setAxisProps(props, options.XAxis, options.YAxis)
 	}
 	vb.config.Renderers = append(vb.config.Renderers, RendererConfig{
 		Type:          "barChart",
@@ -400,7 +641,8 @@ func (vb *VisualizationBuilder) AddBarChart(partitionName string, x, y, width, h
 }
 
 // AddLineChart appends a rolling line plot of the bound partition's first
-// state value over time. The renderer keeps the most recent 100 samples.
+// state value over time. By default the chart shows the most recent 100
+// samples; see ChartOptions.History for longer or time-based windows.
 func (vb *VisualizationBuilder) AddLineChart(partitionName string, x, y, width, height int, options *ChartOptions) *VisualizationBuilder {
 	props := map[string]interface{}{
 		"x":      x,
@@ -424,6 +666,13 @@ func (vb *VisualizationBuilder) AddLineChart(partitionName string, x, y, width,
 		if options.LineWidth != 0 {
 			props["lineWidth"] = options.LineWidth
 		}
+		if options.XAxisMode != "" {
+			props["xAxisMode"] = options.XAxisMode
+		}
+		if options.History != nil {
+			props["history"] = historyProps(options.History)
+		}
+		setAxisProps(props, options.XAxis, options.YAxis)
 	}
 	vb.config.Renderers = append(vb.config.Renderers, RendererConfig{
 		Type:          "lineChart",
@@ -433,6 +682,150 @@ func (vb *VisualizationBuilder) AddLineChart(partitionName string, x, y, width,
 	return vb
 }
 
+// AddMultiLineChart appends a rolling line plot of several state values on
+// shared axes, one line per series, with a legend naming each labelled
+// series. Series may come from different partitions; samples are placed
+// along the x-axis by cumulative simulation time.
+func (vb *VisualizationBuilder) AddMultiLineChart(x, y, width, height int, series []ChartSeries, options *MultiLineChartOptions) *VisualizationBuilder {
+	seriesProps := make([]map[string]interface{}, 0, len(series))
+	for _, s := range series {
+		sp := map[string]interface{}{
+			"partition": s.Partition,
+			"index":     s.Index,
+		}
+		if s.Color != "" {
+			sp["color"] = s.Color
+		}
+		if s.Label != "" {
+			sp["label"] = s.Label
+		}
+		seriesProps = append(seriesProps, sp)
+	}
+	props := map[string]interface{}{
+		"x":      x,
+		"y":      y,
+		"width":  width,
+		"height": height,
+		"series": seriesProps,
+	}
+	if options != nil {
+		if options.FixedYRange {
+			props["fixedYRange"] = true
+			props["minY"] = options.MinY
+			props["maxY"] = options.MaxY
+		}
+		if options.LineWidth != 0 {
+			props["lineWidth"] = options.LineWidth
+		}
+		if options.HideLegend {
+			props["hideLegend"] = true
+		}
+		if options.TextColor != "" {
+			props["textColor"] = options.TextColor
+		}
+		if options.History != nil {
+			props["history"] = historyProps(options.History)
+		}
+		setAxisProps(props, options.XAxis, options.YAxis)
+	}
+	// Bound to no single partition: the renderer reads each series'
+	// partition history itself and draws whatever has arrived so far.
+	vb.config.Renderers = append(vb.config.Renderers, RendererConfig{
+		Type:          "multiLineChart",
+		PartitionName: "",
+		Properties:    props,
+	})
+	return vb
+}
+
+// AddHistogram appends a live histogram of every value in the bound
+// partition's state vector — e.g. one entry per individual in a
+// population model. Bins, range, normalisation and a reference density
+// overlay are set through options.
+func (vb *VisualizationBuilder) AddHistogram(partitionName string, x, y, width, height int, options *HistogramOptions) *VisualizationBuilder {
+	props := map[string]interface{}{
+		"x":      x,
+		"y":      y,
+		"width":  width,
+		"height": height,
+	}
+	if options != nil {
+		if options.Bins != 0 {
+			props["bins"] = options.Bins
+		}
+		if len(options.Edges) > 0 {
+			props["edges"] = options.Edges
+		}
+		if options.FixedRange {
+			props["fixedRange"] = true
+			props["min"] = options.Min
+			props["max"] = options.Max
+		}
+		if options.MaxY != 0 {
+			props["maxY"] = options.MaxY
+		}
+		if options.Normalized {
+			props["normalized"] = true
+		}
+		if options.Color != "" {
+			props["color"] = options.Color
+		}
+		if options.ReferencePartition != "" {
+			props["referencePartition"] = options.ReferencePartition
+		}
+		if options.ReferenceColor != "" {
+			props["referenceColor"] = options.ReferenceColor
+		}
+		if options.TextColor != "" {
+			props["textColor"] = options.TextColor
+		}
+		setAxisProps(props, options.XAxis, options.YAxis)
+	}
+	vb.config.Renderers = append(vb.config.Renderers, RendererConfig{
+		Type:          "histogram",
+		PartitionName: partitionName,
+		Properties:    props,
+	})
+	return vb
+}
+
+// AddHeatmap appends a grid of coloured cells drawn from the bound
+// partition's state, read as a flattened rows×cols grid in row-major
+// order (cell (r, c) is value r*cols + c). The grid is scaled to fill the
+// given box, so a 200×200 lattice can be drawn at any size.
+func (vb *VisualizationBuilder) AddHeatmap(partitionName string, rows, cols int, x, y, width, height int, options *HeatmapOptions) *VisualizationBuilder {
+	props := map[string]interface{}{
+		"rows":   rows,
+		"cols":   cols,
+		"x":      x,
+		"y":      y,
+		"width":  width,
+		"height": height,
+	}
+	if options != nil {
+		if options.ColorMap != "" {
+			props["colorMap"] = options.ColorMap
+		}
+		if options.FixedRange {
+			props["fixedRange"] = true
+			props["min"] = options.Min
+			props["max"] = options.Max
+		}
+		if len(options.Categories) > 0 {
+			props["categories"] = options.Categories
+		}
+		if options.CellOutline != "" {
+			props["cellOutline"] = options.CellOutline
+		}
+	}
+	vb.config.Renderers = append(vb.config.Renderers, RendererConfig{
+		Type:          "heatmap",
+		PartitionName: partitionName,
+		Properties:    props,
+	})
+	return vb
+}
+
 // AddProgressBar appends a horizontal fill bar driven by the first value of
 // the bound partition's state, scaled by options.MaxValue.
 func (vb *VisualizationBuilder) AddProgressBar(partitionName string, x, y, width, height int, options *ProgressBarOptions) *VisualizationBuilder {
@@ -473,10 +866,12 @@ func (vb *VisualizationBuilder) AddProgressBar(partitionName string, x, y, width
 	return vb
 }
 
-// AddImage appends a sprite or static image. The current runtime renderer
-// draws a placeholder rectangle in place of the image; full image loading
-// has not been implemented yet.
-func (vb *VisualizationBuilder) AddImage(partitionName, imagePath string, x, y int, options *ImageOptions) *VisualizationBuilder {
+// AddImage appends a sprite or static image. imagePath is either a URL
+// (absolute, root-relative or data:) used as is, or a path relative to the
+// working directory that GenerateWidget copies into the output's assets/
+// folder and the widget loads from WidgetOptions.AssetBaseURL. (x, y) is
+// the top-left corner, or the centre on an axis with CenterX/CenterY set.
+func (vb *VisualizationBuilder) AddImage(partitionName, imagePath string, x, y float64, options *ImageOptions) *VisualizationBuilder {
 	props := map[string]interface{}{
 		"imagePath": imagePath,
 		"x":         x,
@@ -501,12 +896,18 @@ func (vb *VisualizationBuilder) AddImage(partitionName, imagePath string, x, y i
 		if options.SpriteSheetY != 0 {
 			props["spriteSheetY"] = options.SpriteSheetY
 		}
+		if options.Frame != 0 {
+			props["frame"] = options.Frame
+		}
 		if options.CenterX {
 			props["centerX"] = options.CenterX
 		}
 		if options.CenterY {
 			props["centerY"] = options.CenterY
 		}
+		if options.FromState {
+			props["fromState"] = options.FromState
+		}
 	}
 	vb.config.Renderers = append(vb.config.Renderers, RendererConfig{
 		Type:          "image",
@@ -547,6 +948,213 @@ func (vb *VisualizationBuilder) AddPointSet(partitionName string, options *Point
 	return vb
 }
 
+// AddScatter appends a data-space scatter plot of the bound partition's
+// state, read as consecutive (x, y[, colour][, size]) tuples. Positions
+// are mapped through the x/y ranges onto the given box; the optional
+// colour channel goes through a colour map and the optional size channel
+// through a radius scale.
+func (vb *VisualizationBuilder) AddScatter(partitionName string, x, y, width, height int, options *ScatterOptions) *VisualizationBuilder {
+	props := map[string]interface{}{
+		"x":      x,
+		"y":      y,
+		"width":  width,
+		"height": height,
+	}
+	if options != nil {
+		if options.FixedRange {
+			props["fixedRange"] = true
+			props["minX"] = options.MinX
+			props["maxX"] = options.MaxX
+			props["minY"] = options.MinY
+			props["maxY"] = options.MaxY
+		}
+		if options.Color != "" {
+			props["color"] = options.Color
+		}
+		if options.ColorChannel {
+			props["colorChannel"] = true
+			props["colorMap"] = options.ColorMap
+			props["colorMin"] = options.ColorMin
+			props["colorMax"] = options.ColorMax
+			if len(options.Categories) > 0 {
+				props["categories"] = options.Categories
+			}
+		}
+		if options.SizeChannel {
+			props["sizeChannel"] = true
+			props["sizeMin"] = options.SizeMin
+			props["sizeMax"] = options.SizeMax
+		}
+		if options.Radius != 0 {
+			props["radius"] = options.Radius
+		}
+		if options.MaxRadius != 0 {
+			props["maxRadius"] = options.MaxRadius
+		}
+		if options.StrokeColor != "" {
+			props["strokeColor"] = options.StrokeColor
+		}
+		if options.TextColor != "" {
+			props["textColor"] = options.TextColor
+		}
+		setAxisProps(props, options.XAxis, options.YAxis)
+	}
+	vb.config.Renderers = append(vb.config.Renderers, RendererConfig{
+		Type:          "scatter",
+		PartitionName: partitionName,
+		Properties:    props,
+	})
+	return vb
+}
+
+// AddNetwork appends a network diagram of nodeCount nodes joined by a
+// static edge list, drawn inside the given box. The bound partition's
+// state supplies per-node values that drive node colour and size (see
+// NetworkOptions); node positions are fixed when the widget is generated,
+// either from options.Positions or by CircleLayout.
+func (vb *VisualizationBuilder) AddNetwork(partitionName string, nodeCount int, edges []NetworkEdge, x, y, width, height int, options *NetworkOptions) *VisualizationBuilder {
+	var positions [][2]float64
+	if options != nil {
+		positions = options.Positions
+	}
+	if positions == nil {
+		positions = CircleLayout(nodeCount)
+	}
+	edgeList := make([][2]int, 0, len(edges))
+	for _, e := range edges {
+		edgeList = append(edgeList, [2]int{e.From, e.To})
+	}
+	props := map[string]interface{}{
+		"x":         x,
+		"y":         y,
+		"width":     width,
+		"height":    height,
+		"positions": positions,
+		"edges":     edgeList,
+	}
+	if options != nil {
+		if options.Color != "" {
+			props["color"] = options.Color
+		}
+		if options.ColorChannel {
+			props["colorChannel"] = true
+			props["colorMap"] = options.ColorMap
+			props["colorMin"] = options.ColorMin
+			props["colorMax"] = options.ColorMax
+			if len(options.Categories) > 0 {
+				props["categories"] = options.Categories
+			}
+		}
+		if options.SizeChannel {
+			props["sizeChannel"] = true
+			props["sizeMin"] = options.SizeMin
+			props["sizeMax"] = options.SizeMax
+		}
+		if options.Radius != 0 {
+			props["radius"] = options.Radius
+		}
+		if options.MaxRadius != 0 {
+			props["maxRadius"] = options.MaxRadius
+		}
+		if options.StrokeColor != "" {
+			props["strokeColor"] = options.StrokeColor
+		}
+		if options.EdgeColor != "" {
+			props["edgeColor"] = options.EdgeColor
+		}
+		if options.EdgeWidth != 0 {
+			props["edgeWidth"] = options.EdgeWidth
+		}
+		if options.WeightPartition != "" {
+			props["weightPartition"] = options.WeightPartition
+			props["maxEdgeWidth"] = options.MaxEdgeWidth
+		}
+	}
+	vb.config.Renderers = append(vb.config.Renderers, RendererConfig{
+		Type:          "network",
+		PartitionName: partitionName,
+		Properties:    props,
+	})
+	return vb
+}
+
+// AddVectorField appends a quiver plot of arrows over the data-space box
+// [MinX, MaxX] × [MinY, MaxY] (y upwards). With options.Field set, the
+// arrows are computed here, at generate time, on a regular grid and drawn
+// statically; partitionName may then be empty. Otherwise the bound
+// partition's state is read as consecutive (x, y, dx, dy) groups.
+func (vb *VisualizationBuilder) AddVectorField(partitionName string, x, y, width, height int, options *VectorFieldOptions) *VisualizationBuilder {
+	if options == nil {
+		options = &VectorFieldOptions{}
+	}
+	minX, maxX, minY, maxY := options.MinX, options.MaxX, options.MinY, options.MaxY
+	if minX == maxX {
+		minX, maxX = 0, 1
+	}
+	if minY == maxY {
+		minY, maxY = 0, 1
+	}
+	props := map[string]interface{}{
+		"x":      x,
+		"y":      y,
+		"width":  width,
+		"height": height,
+		"minX":   minX,
+		"maxX":   maxX,
+		"minY":   minY,
+		"maxY":   maxY,
+	}
+	if options.Field != nil {
+		cols, rows := options.GridCols, options.GridRows
+		if cols <= 0 {
+			cols = 15
+		}
+		if rows <= 0 {
+			rows = 15
+		}
+		// Sample at cell centres so arrows don't sit on the box edge.
+		vectors := make([]float64, 0, 4*cols*rows)
+		for r := 0; r < rows; r++ {
+			for c := 0; c < cols; c++ {
+				px := minX + (float64(c)+0.5)/float64(cols)*(maxX-minX)
+				py := minY + (float64(r)+0.5)/float64(rows)*(maxY-minY)
+				dx, dy := options.Field(px, py)
+				// A singular drift can give NaN or ±Inf, which JSON
+				// can't carry; draw no arrow there instead.
+				if math.IsNaN(dx+dy) || math.IsInf(dx+dy, 0) {
+					dx, dy = 0, 0
+				}
+				vectors = append(vectors, px, py, dx, dy)
+			}
+		}
+		props["vectors"] = vectors
+	}
+	if options.Every > 1 {
+		props["every"] = options.Every
+	}
+	if options.Scale != 0 {
+		props["scale"] = options.Scale
+	}
+	if options.Normalize {
+		props["normalize"] = true
+	}
+	if options.Color != "" {
+		props["color"] = options.Color
+	}
+	if options.LineWidth != 0 {
+		props["lineWidth"] = options.LineWidth
+	}
+	if options.HeadSize != 0 {
+		props["headSize"] = options.HeadSize
+	}
+	vb.config.Renderers = append(vb.config.Renderers, RendererConfig{
+		Type:          "vectorField",
+		PartitionName: partitionName,
+		Properties:    props,
+	})
+	return vb
+}
+
 func (vb *VisualizationBuilder) Build() *VisualizationConfig {
 	return vb.config
 }
@@ -585,6 +1193,185 @@ type ChartOptions struct {
 	ShowLabels  bool
 	LabelFormat string
 	LineWidth   int
+
+	// XAxis and YAxis draw labelled axes inside the chart's box, shrinking
+	// the plot area to make room. Nil leaves that axis undrawn. A bar
+	// chart has no x range, so its x-axis shows only the title.
+	XAxis *AxisOptions
+	YAxis *AxisOptions
+	// XAxisMode selects what a line chart's x-axis measures:
+	// XAxisSampleIndex (the default) or XAxisSimulationTime.
+	XAxisMode string
+
+	// History sets how much of the partition's past a line chart plots.
+	// Nil keeps the last 100 samples.
+	History *HistoryWindow
+}
+
+// HistoryWindow selects the span of samples a rolling chart plots: the
+// last Samples samples, everything within the last Time units of
+// simulation time, or (with Full) the whole run downsampled to keep the
+// chart responsive. Time takes precedence over Samples; Full over both.
+type HistoryWindow struct {
+	Samples int
+	Time    float64
+	Full    bool
+	// MaxPoints bounds a Full history: once twice this many points are
+	// held, every other one is dropped. Defaults to 500.
+	MaxPoints int
+}
+
+// historyProps converts a HistoryWindow into renderer properties.
+func historyProps(h *HistoryWindow) map[string]interface{} {
+	return map[string]interface{}{
+		"samples":   h.Samples,
+		"time":      h.Time,
+		"full":      h.Full,
+		"maxPoints": h.MaxPoints,
+	}
+}
+
+// X-axis modes for line charts.
+const (
+	// XAxisSampleIndex spaces samples evenly, oldest first.
+	XAxisSampleIndex = "index"
+	// XAxisSimulationTime places samples by cumulative simulation time.
+	XAxisSimulationTime = "time"
+)
+
+// AxisOptions configures one chart axis. The zero value draws a bare axis
+// line.
+type AxisOptions struct {
+	Title string
+	// TickCount is the number of evenly spaced, labelled ticks including
+	// both ends of the range; fewer than two draws none.
+	TickCount int
+	// TickFormat is the tick label template, with "{value}" replaced by
+	// the tick value rounded to TickDecimals places. Defaults to
+	// "{value}".
+	TickFormat   string
+	TickDecimals int
+	// Gridlines extends each tick across the plot area.
+	Gridlines bool
+}
+
+// setAxisProps copies axis configuration into a chart renderer's
+// properties, leaving unset axes out.
+func setAxisProps(props map[string]interface{}, xAxis, yAxis *AxisOptions) {
+	for key, a := range map[string]*AxisOptions{"xAxis": xAxis, "yAxis": yAxis} {
+		if a == nil {
+			continue
+		}
+		axis := map[string]interface{}{
+			"title":        a.Title,
+			"tickCount":    a.TickCount,
+			"tickFormat":   a.TickFormat,
+			"tickDecimals": a.TickDecimals,
+			"gridlines":    a.Gridlines,
+		}
+		if a.TickFormat == "" {
+			axis["tickFormat"] = "{value}"
+		}
+		props[key] = axis
+	}
+}
+
+// ChartSeries is one line of a multi-series chart: state value Index of
+// Partition, drawn in Color and named Label in the legend. An empty Color
+// picks the next colour from a built-in palette; an empty Label leaves the
+// series out of the legend.
+type ChartSeries struct {
+	Partition string
+	Index     int
+	Color     string
+	Label     string
+}
+
+type MultiLineChartOptions struct {
+	// FixedYRange pins the y-axis to [MinY, MaxY]; values outside it are
+	// clipped to the chart edge. Otherwise the range tracks the plotted
+	// data.
+	FixedYRange bool
+	MinY        float64
+	MaxY        float64
+	LineWidth   int
+	HideLegend  bool
+	// TextColor colours the legend text and the axes.
+	TextColor string
+
+	// XAxis and YAxis are as for ChartOptions. The x-axis always measures
+	// simulation time, so that series from different partitions line up.
+	XAxis *AxisOptions
+	YAxis *AxisOptions
+
+	// History is as for ChartOptions and applies to every series.
+	History *HistoryWindow
+}
+
+type HistogramOptions struct {
+	// Bins is the number of equal-width bins across the range (default
+	// 20). Edges, if set, gives explicit ascending bin edges instead and
+	// overrides Bins and the range.
+	Bins  int
+	Edges []float64
+
+	// FixedRange bins over [Min, Max], ignoring values outside it.
+	// Otherwise the range follows the smallest and largest current value.
+	FixedRange bool
+	Min        float64
+	Max        float64
+
+	// MaxY pins the top of the y-axis; zero scales it to the tallest bar.
+	MaxY float64
+
+	// Normalized plots a probability density (count / (total × bin
+	// width)) rather than raw counts, so the bars are comparable with a
+	// reference density.
+	Normalized bool
+	Color      string
+
+	// ReferencePartition names a partition whose state is a density
+	// sampled at evenly spaced points from the first bin edge to the last,
+	// drawn as a line over the bars. Usually paired with Normalized.
+	ReferencePartition string
+	ReferenceColor     string
+
+	// TextColor colours the axes; XAxis and YAxis are as for ChartOptions.
+	TextColor string
+	XAxis     *AxisOptions
+	YAxis     *AxisOptions
+}
+
+// Colour maps for AddHeatmap.
+const (
+	// ColorMapViridis runs from dark purple (low) to yellow (high).
+	ColorMapViridis = "viridis"
+	// ColorMapDiverging runs blue through white (the middle of the range)
+	// to red; give it a range symmetric about the neutral value.
+	ColorMapDiverging = "diverging"
+	// ColorMapCategorical gives each integer value its own colour, taken
+	// from HeatmapOptions.Categories or a built-in palette.
+	ColorMapCategorical = "categorical"
+)
+
+type HeatmapOptions struct {
+	// ColorMap is one of the ColorMap* constants; viridis by default.
+	ColorMap string
+
+	// FixedRange maps [Min, Max] onto the colour map, clamping values
+	// outside it. Otherwise the range follows the current grid's values.
+	// Categorical maps ignore the range.
+	FixedRange bool
+	Min        float64
+	Max        float64
+
+	// Categories are the colours for values 0, 1, 2, … under
+	// ColorMapCategorical, each written as #rrggbb (not rgb(), a colour
+	// name or a theme token). Values beyond the list wrap around.
+	Categories []string
+
+	// CellOutline, if set, is the colour of a 1px line around every cell.
+	CellOutline string
 }
 
 type ProgressBarOptions struct {
@@ -598,14 +1385,33 @@ type ProgressBarOptions struct {
 }
 
 type ImageOptions struct {
-	Width        int
-	Height       int
-	Rotation     float64
-	Opacity      float64
+	// Width and Height are in canvas pixels, or world units under
+	// WithWorld. With neither set the image (or frame) is drawn at its
+	// natural pixel size; with one set the other keeps its aspect ratio.
+	Width  float64
+	Height float64
+
+	// Rotation is in radians, clockwise on screen, about the image's
+	// centre. Opacity defaults to 1.
+	Rotation float64
+	Opacity  float64
+
+	// SpriteSheetX and SpriteSheetY split the image into a grid of that
+	// many columns and rows of equal frames; Frame picks one, counting
+	// along each row from 0.
 	SpriteSheetX int
 	SpriteSheetY int
-	CenterX      bool
-	CenterY      bool
+	Frame        int
+
+	// CenterX and CenterY make (x, y) the image's centre on that axis
+	// rather than its left or top edge.
+	CenterX bool
+	CenterY bool
+
+	// FromState reads the bound partition's state as [x, y, rotation,
+	// frame], overriding the static values. Trailing entries may be
+	// omitted.
+	FromState bool
 }
 
 type PointSetOptions struct {
@@ -616,6 +1422,122 @@ type PointSetOptions struct {
 	Radius      int
 }
 
+type ScatterOptions struct {
+	// FixedRange maps [MinX, MaxX] × [MinY, MaxY] onto the plot area,
+	// with y increasing upwards; points outside it are not drawn.
+	// Otherwise both ranges follow the current points.
+	FixedRange bool
+	MinX       float64
+	MaxX       float64
+	MinY       float64
+	MaxY       float64
+
+	// Color fills every point when there is no colour channel.
+	Color string
+
+	// ColorChannel adds a third value to each tuple, coloured through
+	// ColorMap (a ColorMap* constant; viridis by default) over
+	// [ColorMin, ColorMax]. Equal bounds mean the range follows the data.
+	// Categories is as for HeatmapOptions.
+	ColorChannel bool
+	ColorMap     string
+	ColorMin     float64
+	ColorMax     float64
+	Categories   []string
+
+	// SizeChannel adds a further value to each tuple, scaled from
+	// [SizeMin, SizeMax] onto a radius between Radius and MaxRadius.
+	// Equal bounds mean the range follows the data.
+	SizeChannel bool
+	SizeMin     float64
+	SizeMax     float64
+
+	// Radius is the point radius in pixels (default 3), and the smallest
+	// radius under a size channel. MaxRadius defaults to four times it.
+	Radius      float64
+	MaxRadius   float64
+	StrokeColor string
+
+	// TextColor colours the axes; XAxis and YAxis are as for ChartOptions.
+	TextColor string
+	XAxis     *AxisOptions
+	YAxis     *AxisOptions
+}
+
+type NetworkOptions struct {
+	// Positions gives each node's position in the unit square, mapped onto
+	// the renderer's box; (0, 0) is top-left. Nil uses CircleLayout; pass
+	// ForceLayout(n, edges, 0) for a force-directed arrangement.
+	Positions [][2]float64
+
+	// Color fills every node when there is no colour channel.
+	Color string
+
+	// The bound partition's state is read as one tuple per node, holding
+	// a colour value if ColorChannel is set followed by a size value if
+	// SizeChannel is set. The channels behave as for ScatterOptions.
+	ColorChannel bool
+	ColorMap     string
+	ColorMin     float64
+	ColorMax     float64
+	Categories   []string
+	SizeChannel  bool
+	SizeMin      float64
+	SizeMax      float64
+
+	// Radius is the node radius in pixels (default 6), and the smallest
+	// radius under a size channel. MaxRadius defaults to twice it.
+	Radius      float64
+	MaxRadius   float64
+	StrokeColor string
+
+	EdgeColor string
+	// EdgeWidth is the edge line width (default 1).
+	EdgeWidth float64
+
+	// WeightPartition names a partition whose state holds one weight per
+	// edge, in edge-list order. Weights are scaled from their current
+	// range onto a line width between EdgeWidth and MaxEdgeWidth (default
+	// four times EdgeWidth).
+	WeightPartition string
+	MaxEdgeWidth    float64
+}
+
+type VectorFieldOptions struct {
+	// MinX, MaxX, MinY and MaxY are the data-space extent of the box;
+	// equal bounds default to [0, 1].
+	MinX float64
+	MaxX float64
+	MinY float64
+	MaxY float64
+
+	// Field, if set, is evaluated at generate time at the centre of each
+	// cell of a GridCols × GridRows grid (15 × 15 by default) and the
+	// resulting arrows are drawn instead of the partition's state — e.g.
+	// the deterministic drift of an SDE under a trajectory plot.
+	Field    func(x, y float64) (dx, dy float64)
+	GridCols int
+	GridRows int
+
+	// Every thins a state-driven field by drawing only every n-th vector.
+	Every int
+
+	// Scale is the arrow length, in the box's data units, per unit of
+	// vector magnitude: (dx, dy) is drawn as (Scale·dx, Scale·dy) in data
+	// space, so its length in pixels also depends on the box. Zero scales
+	// the longest arrow to fit the spacing between arrows.
+	//
+	// Normalize draws every arrow at that spacing, showing direction only;
+	// Scale is then ignored.
+	Scale     float64
+	Normalize bool
+
+	Color     string
+	LineWidth float64
+	// HeadSize is the arrowhead length in pixels (default 5).
+	HeadSize float64
+}
+
 // ConfigBuilder is a small fluent helper for assembling a Config. Like
 // VisualizationBuilder, it performs no validation; the only invariant it
 // enforces is that the slice fields start non-nil.
@@ -658,6 +1580,13 @@ func (gb *ConfigBuilder) WithVisualization(config *VisualizationConfig) *ConfigB
 	return gb
 }
 
+// WithNamedVisualization adds a further canvas next to the primary one.
+// See Config.Canvases.
+func (gb *ConfigBuilder) WithNamedVisualization(name string, config *VisualizationConfig) *ConfigBuilder {
+	gb.config.Canvases = append(gb.config.Canvases, NamedCanvas{Name: name, Visualization: config})
+	return gb
+}
+
 // WithSimulation registers the per-step simulation builder. The runtime
 // calls this once at startup to obtain a fresh stochadex ConfigGenerator
 // (with its partitions and simulation already declared); the runtime then
@@ -676,6 +1605,13 @@ func (gb *ConfigBuilder) WithSlider(s Slider) *ConfigBuilder {
 	return gb
 }
 
+// WithToggle appends a checkbox to the dashboard's Live controls panel.
+// The toggle drives Partition[ValueIndex] with 1 when checked, 0 when not.
+func (gb *ConfigBuilder) WithToggle(t Toggle) *ConfigBuilder {
+	gb.config.Toggles = append(gb.config.Toggles, t)
+	return gb
+}
+
 // WithReadout appends a DOM readout that displays formatted values from
 // one partition's most-recent state. Defaults to 2-decimal value
 // formatting if Readout.Decimals is zero.
@@ -684,6 +1620,56 @@ func (gb *ConfigBuilder) WithReadout(r Readout) *ConfigBuilder {
 	return gb
 }
 
+// WithPreset appends a preset button to the controls panel. Keys of
+// values are control names; clicking the button sets those controls and
+// publishes the new action values without restarting the simulation.
+func (gb *ConfigBuilder) WithPreset(name string, values map[string]float64) *ConfigBuilder {
+	gb.config.Presets = append(gb.config.Presets, Preset{Name: name, Values: values})
+	return gb
+}
+
+// WithResettingPreset is WithPreset for presets that should also restart
+// the simulation from its initial state when applied.
+func (gb *ConfigBuilder) WithResettingPreset(name string, values map[string]float64) *ConfigBuilder {
+	gb.config.Presets = append(gb.config.Presets, Preset{Name: name, Values: values, Reset: true})
+	return gb
+}
+
+// WithPresetSpec appends a fully specified Preset, for presets that need
+// fields WithPreset doesn't take (e.g. VisibleWhen).
+func (gb *ConfigBuilder) WithPresetSpec(p Preset) *ConfigBuilder {
+	gb.config.Presets = append(gb.config.Presets, p)
+	return gb
+}
+
+// WithControlLink declares a prose link with the given text that sets the
+// listed controls on click. Its markup is written to links.html.
+func (gb *ConfigBuilder) WithControlLink(text string, values map[string]float64) *ConfigBuilder {
+	gb.config.Links = append(gb.config.Links, ControlLink{Text: text, Values: values})
+	return gb
+}
+
+// WithResettingControlLink is WithControlLink for links that should also
+// restart the simulation from its initial state when clicked.
+func (gb *ConfigBuilder) WithResettingControlLink(text string, values map[string]float64) *ConfigBuilder {
+	gb.config.Links = append(gb.config.Links, ControlLink{Text: text, Values: values, Reset: true})
+	return gb
+}
+
+// WithScenario appends a scripted scenario and its "play" button to the
+// controls panel. See Scenario for the timing semantics.
+func (gb *ConfigBuilder) WithScenario(sc Scenario) *ConfigBuilder {
+	gb.config.Scenarios = append(gb.config.Scenarios, sc)
+	return gb
+}
+
+// WithLayout replaces the default two-panel arrangement with a custom
+// one, typically assembled with NewLayoutBuilder.
+func (gb *ConfigBuilder) WithLayout(layout *Layout) *ConfigBuilder {
+	gb.config.Layout = layout
+	return gb
+}
+
 // WithResetButton enables the "Reset simulation" button in the controls
 // panel. The button terminates and re-launches the wasm worker so the
 // simulation restarts from its initial state.
@@ -0,0 +1,275 @@
+package dashboard_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/umbralcalc/dexetera/pkg/dashboard"
+)
+
+func TestAddMultiLineChart(t *testing.T) {
+	vis := dashboard.NewVisualizationBuilder().
+		AddMultiLineChart(10, 20, 300, 120, []dashboard.ChartSeries{
+			{Partition: "sir", Index: 0, Label: "S"},
+			{Partition: "sir", Index: 1, Color: "#e06666", Label: "I"},
+			{Partition: "sir", Index: 2},
+		}, &dashboard.MultiLineChartOptions{FixedYRange: true, MinY: 0, MaxY: 1}).
+		Build()
+
+	if len(vis.Renderers) != 1 {
+		t.Fatalf("expected one renderer, got %d", len(vis.Renderers))
+	}
+	r := vis.Renderers[0]
+	if r.Type != "multiLineChart" || r.PartitionName != "" {
+		t.Fatalf("unexpected renderer %q bound to %q", r.Type, r.PartitionName)
+	}
+	series, ok := r.Properties["series"].([]map[string]interface{})
+	if !ok || len(series) != 3 {
+		t.Fatalf("expected three series, got %#v", r.Properties["series"])
+	}
+	if series[1]["index"] != 1 || series[1]["color"] != "#e06666" || series[1]["label"] != "I" {
+		t.Errorf("unexpected second series %#v", series[1])
+	}
+	if _, ok := series[2]["label"]; ok {
+		t.Errorf("unlabelled series should carry no label, got %#v", series[2])
+	}
+	if r.Properties["fixedYRange"] != true || r.Properties["maxY"] != 1.0 {
+		t.Errorf("expected a fixed [0, 1] y-range, got %#v", r.Properties)
+	}
+}
+
+func TestAddLineChart_Axes(t *testing.T) {
+	vis := dashboard.NewVisualizationBuilder().
+		AddLineChart("population", 0, 0, 300, 120, &dashboard.ChartOptions{
+			XAxisMode: dashboard.XAxisSimulationTime,
+			XAxis:     &dashboard.AxisOptions{Title: "time", TickCount: 5},
+			YAxis:     &dashboard.AxisOptions{TickCount: 3, TickFormat: "{value}%", TickDecimals: 1, Gridlines: true},
+		}).
+		AddLineChart("population", 0, 0, 300, 120, &dashboard.ChartOptions{Color: "#000"}).
+		Build()
+
+	props := vis.Renderers[0].Properties
+	if props["xAxisMode"] != "time" {
+		t.Errorf("expected time x-axis, got %#v", props["xAxisMode"])
+	}
+	xAxis, ok := props["xAxis"].(map[string]interface{})
+	if !ok || xAxis["title"] != "time" || xAxis["tickFormat"] != "{value}" {
+		t.Errorf("unexpected x-axis %#v", props["xAxis"])
+	}
+	yAxis, ok := props["yAxis"].(map[string]interface{})
+	if !ok || yAxis["tickFormat"] != "{value}%" || yAxis["gridlines"] != true {
+		t.Errorf("unexpected y-axis %#v", props["yAxis"])
+	}
+
+	bare := vis.Renderers[1].Properties
+	if _, ok := bare["xAxis"]; ok {
+		t.Errorf("chart without axes should carry no xAxis, got %#v", bare["xAxis"])
+	}
+}
+
+func TestAddLineChart_History(t *testing.T) {
+	vis := dashboard.NewVisualizationBuilder().
+		AddLineChart("population", 0, 0, 300, 120, &dashboard.ChartOptions{
+			History: &dashboard.HistoryWindow{Full: true, MaxPoints: 200},
+		}).
+		AddMultiLineChart(0, 0, 300, 120, []dashboard.ChartSeries{{Partition: "population"}},
+			&dashboard.MultiLineChartOptions{History: &dashboard.HistoryWindow{Time: 30}}).
+		Build()
+
+	line, ok := vis.Renderers[0].Properties["history"].(map[string]interface{})
+	if !ok || line["full"] != true || line["maxPoints"] != 200 {
+		t.Errorf("unexpected line chart history %#v", vis.Renderers[0].Properties["history"])
+	}
+	multi, ok := vis.Renderers[1].Properties["history"].(map[string]interface{})
+	if !ok || multi["time"] != 30.0 {
+		t.Errorf("unexpected multi-line chart history %#v", vis.Renderers[1].Properties["history"])
+	}
+}
+
+func TestAddHistogram(t *testing.T) {
+	vis := dashboard.NewVisualizationBuilder().
+		AddHistogram("ages", 0, 0, 300, 120, &dashboard.HistogramOptions{
+			Edges:              []float64{0, 18, 65, 100},
+			Normalized:         true,
+			ReferencePartition: "age_density",
+		}).
+		AddHistogram("ages", 0, 0, 300, 120, nil).
+		Build()
+
+	props := vis.Renderers[0].Properties
+	if vis.Renderers[0].Type != "histogram" || vis.Renderers[0].PartitionName != "ages" {
+		t.Fatalf("unexpected renderer %#v", vis.Renderers[0])
+	}
+	if edges, ok := props["edges"].([]float64); !ok || len(edges) != 4 {
+		t.Errorf("expected explicit edges, got %#v", props["edges"])
+	}
+	if props["normalized"] != true || props["referencePartition"] != "age_density" {
+		t.Errorf("unexpected properties %#v", props)
+	}
+	if _, ok := vis.Renderers[1].Properties["bins"]; ok {
+		t.Errorf("default histogram should leave bins to the renderer")
+	}
+}
+
+func TestAddHeatmap(t *testing.T) {
+	vis := dashboard.NewVisualizationBuilder().
+		AddHeatmap("lattice", 200, 100, 0, 0, 400, 200, &dashboard.HeatmapOptions{
+			ColorMap:   dashboard.ColorMapDiverging,
+			FixedRange: true,
+			Min:        -1,
+			Max:        1,
+		}).
+		Build()
+
+	r := vis.Renderers[0]
+	if r.Type != "heatmap" || r.Properties["rows"] != 200 || r.Properties["cols"] != 100 {
+		t.Fatalf("unexpected renderer %#v", r)
+	}
+	if r.Properties["colorMap"] != "diverging" || r.Properties["min"] != -1.0 {
+		t.Errorf("unexpected properties %#v", r.Properties)
+	}
+	if _, ok := r.Properties["cellOutline"]; ok {
+		t.Errorf("outline should be off unless set")
+	}
+
+	t.Run("non-hex category colour is rejected", func(t *testing.T) {
+		vis := dashboard.NewVisualizationBuilder().
+			AddHeatmap("lattice", 2, 2, 0, 0, 100, 100, &dashboard.HeatmapOptions{
+				ColorMap:   dashboard.ColorMapCategorical,
+				Categories: []string{"#000000", "rgb(255, 0, 0)"},
+			}).
+			Build()
+		expectError(t, baseBuilder().WithVisualization(vis).Build().Validate(), `category colour "rgb(255, 0, 0)" must be #rrggbb`)
+	})
+}
+
+func TestAddScatter(t *testing.T) {
+	vis := dashboard.NewVisualizationBuilder().
+		AddScatter("agents", 0, 0, 300, 300, &dashboard.ScatterOptions{
+			FixedRange:   true,
+			MaxX:         10,
+			MaxY:         10,
+			ColorChannel: true,
+			ColorMap:     dashboard.ColorMapCategorical,
+			SizeChannel:  true,
+			SizeMax:      5,
+		}).
+		AddScatter("agents", 0, 0, 300, 300, nil).
+		Build()
+
+	props := vis.Renderers[0].Properties
+	if vis.Renderers[0].Type != "scatter" || props["maxX"] != 10.0 {
+		t.Fatalf("unexpected renderer %#v", vis.Renderers[0])
+	}
+	if props["colorChannel"] != true || props["colorMap"] != "categorical" || props["sizeMax"] != 5.0 {
+		t.Errorf("unexpected channel properties %#v", props)
+	}
+	if _, ok := vis.Renderers[1].Properties["colorChannel"]; ok {
+		t.Errorf("plain scatter should have no colour channel")
+	}
+}
+
+func TestAddVectorField(t *testing.T) {
+	t.Run("field is sampled at generate time", func(t *testing.T) {
+		vis := dashboard.NewVisualizationBuilder().
+			AddVectorField("", 0, 0, 200, 200, &dashboard.VectorFieldOptions{
+				MinX: -1, MaxX: 1, MinY: -1, MaxY: 1,
+				Field:    func(x, y float64) (float64, float64) { return -y, x },
+				GridCols: 2,
+				GridRows: 2,
+			}).
+			Build()
+		vectors, ok := vis.Renderers[0].Properties["vectors"].([]float64)
+		if !ok || len(vectors) != 16 {
+			t.Fatalf("expected 4 vectors of 4 values, got %#v", vis.Renderers[0].Properties["vectors"])
+		}
+		// First cell centre is (-0.5, -0.5); the rotation field gives (0.5, -0.5).
+		want := []float64{-0.5, -0.5, 0.5, -0.5}
+		for i, v := range want {
+			if vectors[i] != v {
+				t.Errorf("vectors[%d] = %v, want %v", i, vectors[i], v)
+			}
+		}
+	})
+
+	t.Run("non-finite samples draw no arrow", func(t *testing.T) {
+		vis := dashboard.NewVisualizationBuilder().
+			AddVectorField("", 0, 0, 200, 200, &dashboard.VectorFieldOptions{
+				MinX: -1, MaxX: 1, MinY: -1, MaxY: 1,
+				Field:    func(x, y float64) (float64, float64) { return 1 / (x + 0.5), math.NaN() },
+				GridCols: 2,
+				GridRows: 2,
+			}).
+			Build()
+		vectors := vis.Renderers[0].Properties["vectors"].([]float64)
+		if vectors[2] != 0 || vectors[3] != 0 {
+			t.Errorf("expected a zero vector at the singularity, got (%v, %v)", vectors[2], vectors[3])
+		}
+		if err := dashboard.GenerateWidget(baseBuilder().WithVisualization(vis).Build(), dashboard.WidgetOptions{OutputDir: t.TempDir()}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("empty range is rejected", func(t *testing.T) {
+		vis := dashboard.NewVisualizationBuilder().
+			AddVectorField("drift", 0, 0, 200, 200, nil).
+			Build()
+		vis.Renderers[0].Properties["maxY"] = 0.0
+		expectError(t, baseBuilder().WithVisualization(vis).Build().Validate(), "range [0, 1] x [0, 0] must be non-empty")
+	})
+
+	t.Run("state-driven field has no static vectors", func(t *testing.T) {
+		vis := dashboard.NewVisualizationBuilder().
+			AddVectorField("drift", 0, 0, 200, 200, nil).
+			Build()
+		props := vis.Renderers[0].Properties
+		if _, ok := props["vectors"]; ok {
+			t.Errorf("expected no static vectors, got %#v", props["vectors"])
+		}
+		if props["maxX"] != 1.0 || props["maxY"] != 1.0 {
+			t.Errorf("expected the unit box by default, got %#v", props)
+		}
+	})
+}
+
+func TestBind(t *testing.T) {
+	vis := dashboard.NewVisualizationBuilder().
+		AddCircle("", 0, 0, 5, nil).
+		Bind("x", dashboard.StateRef{Partition: "pos", Index: 2, Scale: 10}).
+		Bind("fillColor", dashboard.StateRef{Partition: "pos", Template: "rgba(0,0,0,{value})", Decimals: 2}).
+		Build()
+	b := vis.Renderers[0].Bindings
+	if len(b) != 2 || b["x"].Index != 2 || b["x"].Scale != 10 || b["fillColor"].Template == "" {
+		t.Fatalf("unexpected bindings %#v", b)
+	}
+
+	t.Run("bound config validates", func(t *testing.T) {
+		if err := baseBuilder().WithVisualization(vis).Build().Validate(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("binding without a partition is rejected", func(t *testing.T) {
+		vis := dashboard.NewVisualizationBuilder().
+			AddCircle("", 0, 0, 5, nil).
+			Bind("radius", dashboard.StateRef{Index: 0}).
+			Build()
+		expectError(t, baseBuilder().WithVisualization(vis).Build().Validate(), `binds "radius" without a partition`)
+	})
+
+	t.Run("bind before any renderer is rejected", func(t *testing.T) {
+		vis := dashboard.NewVisualizationBuilder().
+			Bind("x", dashboard.StateRef{Partition: "pos"}).
+			AddCircle("", 0, 0, 5, nil).
+			Build()
+		expectError(t, baseBuilder().WithVisualization(vis).Build().Validate(), `Bind("x") was called before any renderer was added`)
+	})
+
+	t.Run("negative index is rejected", func(t *testing.T) {
+		vis := dashboard.NewVisualizationBuilder().
+			AddCircle("ball", 0, 0, 5, nil).
+			Bind("y", dashboard.StateRef{Index: -1}).
+			Build()
+		expectError(t, baseBuilder().WithVisualization(vis).Build().Validate(), "negative index -1")
+	})
+}
@@ -0,0 +1,92 @@
+package dashboard
+
+import (
+	"crypto/sha512"
+	"encoding/base64"
+	"net/url"
+	"strings"
+)
+
+// externalFiles are a StrictCSP widget's style and script, served as
+// files and referenced by URL and integrity hash.
+type externalFiles struct {
+	ScriptName string
+	Style      string
+	Script     string
+	StyleURL   string
+	StyleSRI   string
+	ScriptURL  string
+	ScriptSRI  string
+}
+
+// write saves the files into dir; a nil receiver writes nothing.
+func (f *externalFiles) write(dir string) error {
+	if f == nil {
+		return nil
+	}
+	if err := writeFile(dir, "widget.css", f.Style); err != nil {
+		return err
+	}
+	return writeFile(dir, f.ScriptName, f.Script)
+}
+
+// sriHash is the Subresource Integrity value for data.
+func sriHash(data []byte) string {
+	sum := sha512.Sum384(data)
+	return "sha384-" + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// RecommendedCSP returns the Content-Security-Policy directives a page
+// embedding the StrictCSP widget for config needs, for merging into the
+// page's own policy. 'wasm-unsafe-eval' is unavoidable: the simulation
+// is compiled WebAssembly.
+func RecommendedCSP(config *Config, opts WidgetOptions) string {
+	opts.applyDefaults(config.Name)
+	rendererURL := opts.RuntimeBaseURL + "renderer.js"
+	workerURL := opts.RuntimeBaseURL + "worker.js"
+	if p := opts.published; p != nil {
+		rendererURL, workerURL = p.Renderer, p.Worker
+	}
+
+	connect := []string{cspSource(opts.WasmURL)}
+	if config.Driver.Kind == "websocket" || config.Driver.Kind == "" {
+		ws, _ := config.Driver.Options["url"].(string)
+		if ws == "" {
+			ws = "ws://localhost:2112"
+		}
+		connect = append(connect, cspSource(ws))
+	}
+	directives := []struct {
+		name    string
+		sources []string
+	}{
+		{"script-src", []string{cspSource(opts.WidgetBaseURL), cspSource(rendererURL), cspSource(workerURL), "'wasm-unsafe-eval'"}},
+		{"style-src", []string{cspSource(opts.WidgetBaseURL)}},
+		{"worker-src", []string{cspSource(workerURL)}},
+		{"connect-src", connect},
+		{"img-src", []string{cspSource(opts.AssetBaseURL), "data:"}},
+	}
+	parts := make([]string, 0, len(directives))
+	for _, d := range directives {
+		seen := make(map[string]struct{}, len(d.sources))
+		var unique []string
+		for _, src := range d.sources {
+			if _, dup := seen[src]; !dup {
+				seen[src] = struct{}{}
+				unique = append(unique, src)
+			}
+		}
+		parts = append(parts, d.name+" "+strings.Join(unique, " "))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// cspSource is the CSP source expression covering a URL: its origin, or
+// 'self' for a relative or root-relative URL.
+func cspSource(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return "'self'"
+	}
+	return u.Scheme + "://" + u.Host
+}
@@ -0,0 +1,78 @@
+package dashboard_test
+
+import (
+	"crypto/sha512"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/umbralcalc/dexetera/pkg/dashboard"
+)
+
+func TestGenerateWidget_StrictCSP(t *testing.T) {
+	dir := t.TempDir()
+	opts := dashboard.WidgetOptions{
+		OutputDir:      dir,
+		StrictCSP:      true,
+		RuntimeDir:     "../../runtime",
+		WidgetBaseURL:  "https://cdn.example.com/widgets/test/",
+		RuntimeBaseURL: "https://cdn.example.com/runtime/",
+		WasmURL:        "/wasm/main.wasm",
+	}
+	if err := dashboard.GenerateWidget(baseBuilder().Build(), opts); err != nil {
+		t.Fatalf("GenerateWidget: %v", err)
+	}
+	read := func(name string) string {
+		t.Helper()
+		raw, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(raw)
+	}
+	sri := func(s string) string {
+		sum := sha512.Sum384([]byte(s))
+		return "sha384-" + base64.StdEncoding.EncodeToString(sum[:])
+	}
+
+	html := read("widget.html")
+	if strings.Contains(html, "<style") || strings.Contains(html, "<script>") || strings.Contains(html, "style=") {
+		t.Errorf("strict CSP widget.html still has inline style or script:\n%s", html)
+	}
+	for _, want := range []string{
+		`href="https://cdn.example.com/widgets/test/widget.css" integrity="` + sri(read("widget.css")) + `"`,
+		`src="https://cdn.example.com/widgets/test/widget.js" integrity="` + sri(read("widget.js")) + `"`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("widget.html is missing %q", want)
+		}
+	}
+	renderer, err := os.ReadFile("../../runtime/renderer.js")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(read("widget.js"), "var RENDERER_SRI = '"+sri(string(renderer))+"';") {
+		t.Errorf("widget.js does not pin renderer.js by integrity hash")
+	}
+	if !strings.Contains(read("test.html"), `src="./test.js"`) {
+		t.Errorf("test.html should load its own local script")
+	}
+
+	want := "script-src https://cdn.example.com 'wasm-unsafe-eval'; style-src https://cdn.example.com; " +
+		"worker-src https://cdn.example.com; connect-src 'self'; img-src 'self' data:"
+	if got := strings.TrimSpace(read("csp.txt")); got != want {
+		t.Errorf("csp.txt = %q, want %q", got, want)
+	}
+
+	t.Run("bundle is rejected", func(t *testing.T) {
+		opts := opts
+		opts.Bundle = true
+		opts.OutputDir = filepath.Join(t.TempDir(), "widget")
+		expectError(t, dashboard.GenerateWidget(baseBuilder().Build(), opts), "cannot be combined")
+		if _, err := os.Stat(opts.OutputDir); !os.IsNotExist(err) {
+			t.Errorf("a rejected combination still created the output directory")
+		}
+	})
+}
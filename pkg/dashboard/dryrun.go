@@ -5,39 +5,48 @@ import (
 )
 
 // prepareSimulationRun materialises a stochadex Settings + Implementations
-// pair from cfg with output wiring stubbed out, then seeds every action
-// partition with actionStateValues so subsequent steps see those values as
-// if they had arrived from a live action source. The two return values are
-// the same pair that PartitionCoordinator and RunWithHarnesses both want.
+// pair from cfg with output wiring stubbed out, then seeds each partition
+// named in actionStateValues with its values so subsequent steps see them
+// as if they had arrived from a live action source. The two return values
+// are the same pair that PartitionCoordinator and RunWithHarnesses both
+// want.
 //
 // Output is set to the nil pair on purpose: dry runs and harness checks
 // don't want noise on stdout, they just want the iteration graph to
 // execute.
 func prepareSimulationRun(
 	cfg *Config,
-	actionStateValues []float64,
+	actionStateValues map[string][]float64,
 ) (*simulator.Settings, *simulator.Implementations) {
 	settings, implementations := cfg.SimulationGenerator().GenerateConfigs()
 	implementations.OutputCondition = &simulator.NilOutputCondition{}
 	implementations.OutputFunction = &simulator.NilOutputFunction{}
 
-	for _, name := range cfg.ActionStatePartitionNames {
-		for i := range settings.Iterations {
-			if settings.Iterations[i].Name == name {
-				settings.Iterations[i].Params.Set("action_state_values", actionStateValues)
-			}
+	for i := range settings.Iterations {
+		if values, ok := actionStateValues[settings.Iterations[i].Name]; ok {
+			settings.Iterations[i].Params.Set("action_state_values", values)
 		}
 	}
 	return settings, implementations
 }
 
+// everyActionPartition seeds every action partition of cfg with the same
+// values.
+func everyActionPartition(cfg *Config, values []float64) map[string][]float64 {
+	seeded := make(map[string][]float64, len(cfg.ActionStatePartitionNames))
+	for _, name := range cfg.ActionStatePartitionNames {
+		seeded[name] = values
+	}
+	return seeded
+}
+
 // FullDryRun executes one complete coordinator run against the simulation
 // described by cfg, with every action partition pre-seeded to
 // actionStateValues. Production code paths use this entry point (a real
 // PartitionCoordinator, not the harness machinery); test code should
 // prefer VerifyIterationHarness for stronger checks on each iteration.
 func FullDryRun(cfg *Config, actionStateValues []float64) {
-	settings, implementations := prepareSimulationRun(cfg, actionStateValues)
+	settings, implementations := prepareSimulationRun(cfg, everyActionPartition(cfg, actionStateValues))
 	coordinator := simulator.NewPartitionCoordinator(settings, implementations)
 	coordinator.Run()
 }
@@ -48,6 +57,6 @@ func FullDryRun(cfg *Config, actionStateValues []float64) {
 // stochadex relies on (no out-of-bounds history reads, no state-width
 // surprises, etc.). Intended for *_test.go usage.
 func VerifyIterationHarness(cfg *Config, actionStateValues []float64) error {
-	settings, implementations := prepareSimulationRun(cfg, actionStateValues)
+	settings, implementations := prepareSimulationRun(cfg, everyActionPartition(cfg, actionStateValues))
 	return simulator.RunWithHarnesses(settings, implementations)
 }
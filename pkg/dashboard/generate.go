@@ -3,9 +3,14 @@ package dashboard
 import (
 	"encoding/json"
 	"fmt"
+	"html"
 	"log"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 	"text/template"
 )
 
@@ -40,6 +45,75 @@ type WidgetOptions struct {
 	// built wasm, e.g. "/assets/dexetera/widgets/<name>/main.wasm".
 	// Defaults to "./src/main.wasm".
 	WasmURL string
+
+	// AssetBaseURL is the URL prefix the widget loads local images from
+	// (AddImage paths that aren't URLs). GenerateWidget copies those files
+	// into "<OutputDir>/assets/", so for blog embedding sync that folder
+	// and point this at it. Defaults to "./assets/".
+	AssetBaseURL string
+
+	// AssetSourceDir is the directory local image paths are read from.
+	// Defaults to the working directory.
+	AssetSourceDir string
+
+	// Theme styles the widget's panels, controls and theme-token colours.
+	// Defaults to LightTheme.
+	Theme *Theme
+
+	// DarkTheme, if set, replaces Theme while the reader's system prefers
+	// a dark colour scheme (usually &DarkTheme()).
+	DarkTheme *Theme
+
+	// Bundle makes widget.html (and test.html) self-contained: renderer.js,
+	// the worker with its imports and driver, and the gzipped wasm are all
+	// inlined, so the snippet needs no runtime or wasm hosting and
+	// RuntimeBaseURL and WasmURL are ignored. The wasm must already be
+	// built, so run build.sh before generating a bundle.
+	Bundle bool
+
+	// RuntimeDir is where Bundle, StrictCSP and PublishAssets read the
+	// runtime files from. Defaults to "runtime".
+	RuntimeDir string
+
+	// BundleWasmPath is the built wasm Bundle inlines and PublishAssets
+	// publishes. Defaults to "<OutputDir>/src/main.wasm", where build.sh
+	// writes it.
+	BundleWasmPath string
+
+	// StrictCSP writes the widget's style and script to widget.css and
+	// widget.js (test.js for test.html) and references them with
+	// Subresource Integrity hashes, leaving no inline code or style in
+	// the snippet. RecommendedCSP gives the policy it then needs. It
+	// can't be combined with Bundle.
+	StrictCSP bool
+
+	// WidgetBaseURL is the URL prefix StrictCSP's widget.css and widget.js,
+	// or WebComponent's dexetera-widget.js and spec, are served from.
+	// Defaults to "./".
+	WidgetBaseURL string
+
+	// Poster, if set, shows a static image of each canvas, drawn from a
+	// native run of the simulation, until the live canvas takes over,
+	// and in its place where scripts don't run.
+	Poster *PosterOptions
+
+	// MaxCanvasHeight, if set, caps how tall each canvas is displayed, in
+	// CSS pixels, by narrowing it; its drawing resolution is unchanged.
+	MaxCanvasHeight int
+
+	// WebComponent makes widget.html a <dexetera-widget> custom element:
+	// dexetera-widget.js defines the element, which renders into a Shadow
+	// DOM from the <name>.json spec named by its config attribute. It
+	// can't be combined with Bundle or StrictCSP.
+	WebComponent bool
+
+	// published, set by PublishAssets, replaces the runtime and image URLs
+	// with content-hashed ones.
+	published *publishedURLs
+
+	// posterRun, set by PreparePoster, holds the simulation run every
+	// body rendered from these options draws its posters from.
+	posterRun map[string][]posterSample
 }
 
 func (o *WidgetOptions) applyDefaults(name string) {
@@ -55,6 +129,25 @@ func (o *WidgetOptions) applyDefaults(name string) {
 	if o.WasmURL == "" {
 		o.WasmURL = "./src/main.wasm"
 	}
+	if o.AssetBaseURL == "" {
+		o.AssetBaseURL = "./assets/"
+	}
+	if o.AssetSourceDir == "" {
+		o.AssetSourceDir = "."
+	}
+	if o.RuntimeDir == "" {
+		o.RuntimeDir = "runtime"
+	}
+	if o.BundleWasmPath == "" {
+		o.BundleWasmPath = filepath.Join(o.OutputDir, "src", "main.wasm")
+	}
+	if o.WidgetBaseURL == "" {
+		o.WidgetBaseURL = "./"
+	}
+	if o.Theme == nil {
+		light := LightTheme()
+		o.Theme = &light
+	}
 }
 
 // GenerateWidget writes the embeddable widget snippet (widget.html), a
@@ -66,48 +159,141 @@ func (o *WidgetOptions) applyDefaults(name string) {
 //
 //	widget.html  Drop-in <div>+<style>+<script> snippet for embedding.
 //	             References RuntimeBaseURL and WasmURL absolutely so the
-//	             embedding page only needs to host the runtime + wasm.
+//	             embedding page only needs to host the runtime + wasm,
+//	             or inlines both when opts.Bundle is set.
 //	test.html    Standalone HTML page that wraps the widget for local
 //	             preview. Uses local relative paths (../runtime/, ./src/...)
 //	             regardless of opts so that `python3 -m http.server` from
 //	             the repo root can preview without further configuration.
 //	build.sh     Script that compiles cmd/<name>/register_step to
 //	             src/main.wasm.
+//	links.html   One <a> per Config.Links entry, for pasting into the
+//	             prose around the widget. Only written if Links is set.
+//	assets/      Copies of the local image files AddImage renderers
+//	             reference, read from opts.AssetSourceDir.
+//	widget.css,  With opts.StrictCSP: the widget's style and scripts
+//	widget.js,   (test.js for test.html), and the Content-Security-Policy
+//	test.js,     they need.
+//	csp.txt
+//	dexetera-widget.js,  With opts.WebComponent: the custom element
+//	<name>.json,         definition and the specs widget.html and
+//	<name>.test.json     test.html load.
 //
 // The output directory is created if it doesn't exist; existing files
 // in it are overwritten.
 func GenerateWidget(config *Config, opts WidgetOptions) error {
 	opts.applyDefaults(config.Name)
 
+	if err := config.Validate(); err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+	if opts.Bundle && opts.StrictCSP {
+		return fmt.Errorf("Bundle and StrictCSP cannot be combined: a bundle runs inline code")
+	}
+	if opts.WebComponent && (opts.Bundle || opts.StrictCSP) {
+		return fmt.Errorf("WebComponent cannot be combined with Bundle or StrictCSP")
+	}
 	if err := os.MkdirAll(opts.OutputDir, 0755); err != nil {
 		return fmt.Errorf("failed to create output directory: %w", err)
 	}
+	// widget.html and test.html share one poster run.
+	opts, err := PreparePoster(config, opts)
+	if err != nil {
+		return err
+	}
 
-	body, err := renderWidgetBody(config, opts.WidgetID, opts.RuntimeBaseURL, opts.WasmURL)
+	var body string
+	var files *externalFiles
+	if opts.WebComponent {
+		body, err = writeComponent(config, opts, config.Name+".json")
+	} else {
+		body, files, err = renderWidgetBody(config, opts, "widget.js")
+	}
 	if err != nil {
 		return fmt.Errorf("failed to render widget body: %w", err)
 	}
 	if err := writeFile(opts.OutputDir, "widget.html", body); err != nil {
 		return err
 	}
+	if err := files.write(opts.OutputDir); err != nil {
+		return err
+	}
 
 	// test.html: same widget body, but with local relative URLs so the
-	// page works via `python3 -m http.server` from the repo root.
-	testBody, err := renderWidgetBody(config, opts.WidgetID, "../runtime/", "./src/main.wasm")
-	if err != nil {
-		return fmt.Errorf("failed to render test widget body: %w", err)
+	// page works via `python3 -m http.server` from the repo root. A bundle
+	// has no URLs to localise.
+	testBody := body
+	if !opts.Bundle {
+		testOpts := opts
+		testOpts.RuntimeBaseURL = "../runtime/"
+		testOpts.WasmURL = "./src/main.wasm"
+		testOpts.AssetBaseURL = "./assets/"
+		testOpts.published = nil
+		testOpts.WidgetBaseURL = "./"
+		if opts.WebComponent {
+			testBody, err = writeComponent(config, testOpts, config.Name+".test.json")
+		} else {
+			testBody, files, err = renderWidgetBody(config, testOpts, "test.js")
+		}
+		if err != nil {
+			return fmt.Errorf("failed to render test widget body: %w", err)
+		}
+		if err := files.write(opts.OutputDir); err != nil {
+			return err
+		}
 	}
-	testPage := wrapTestHTML(config.Name, testBody)
+	links := ""
+	if len(config.Links) > 0 {
+		links = renderLinks(config, opts.WidgetID)
+	}
+	testPage := wrapTestHTML(config.Name, testBody, links, opts.DarkTheme)
 	if err := writeFile(opts.OutputDir, "test.html", testPage); err != nil {
 		return err
 	}
 
+	if links != "" {
+		if err := writeFile(opts.OutputDir, "links.html", links); err != nil {
+			return err
+		}
+	}
+
+	if err := copyAssets(config, opts.AssetSourceDir, opts.OutputDir); err != nil {
+		return err
+	}
+	if opts.StrictCSP {
+		if err := writeFile(opts.OutputDir, "csp.txt", RecommendedCSP(config, opts)+"\n"); err != nil {
+			return err
+		}
+	}
 	if err := generateBuildScript(opts.OutputDir, config.Name); err != nil {
 		return err
 	}
 	return nil
 }
 
+// RenderWidget returns the snippet GenerateWidget writes to widget.html,
+// without writing anything, for tools that place widgets into pages
+// themselves. StrictCSP and WebComponent snippets need companion files,
+// so RenderWidget rejects them.
+func RenderWidget(config *Config, opts WidgetOptions) (string, error) {
+	opts.applyDefaults(config.Name)
+	if opts.StrictCSP || opts.WebComponent {
+		return "", fmt.Errorf("RenderWidget supports neither StrictCSP nor WebComponent")
+	}
+	if err := config.Validate(); err != nil {
+		return "", fmt.Errorf("invalid config: %w", err)
+	}
+	opts, err := PreparePoster(config, opts)
+	if err != nil {
+		return "", err
+	}
+	body, _, err := renderWidgetBody(config, opts, "widget.js")
+	if err != nil {
+		return "", fmt.Errorf("failed to render widget body: %w", err)
+	}
+	return body, nil
+}
+
 func writeFile(dir, name, contents string) error {
 	path := filepath.Join(dir, name)
 	f, err := os.Create(path)
@@ -124,58 +310,174 @@ func writeFile(dir, name, contents string) error {
 // renderWidgetBody produces the embeddable widget snippet — a single
 // <div> wrapping a scoped <style> block, the panel layout, and an IIFE
 // <script> that loads the runtime, instantiates a worker, and wires up
-// the canvas + sliders + readouts + reset button.
+// the canvas + sliders + readouts + presets + scenarios + reset button.
 //
 // All CSS selectors are prefixed with "#<widgetID>" so the styles stay
 // confined to this widget — multiple dexetera widgets can coexist on the
 // same page without fighting over .panel, .slider, etc.
-func renderWidgetBody(cfg *Config, widgetID, runtimeBase, wasmURL string) (string, error) {
-	visConfig := cfg.VisualizationConfig
-	hasControls := len(cfg.Sliders) > 0 || cfg.ShowReset
+//
+// With opts.StrictCSP the style and script go into external files instead,
+// returned for the caller to write; scriptName names the script file.
+func renderWidgetBody(cfg *Config, opts WidgetOptions, scriptName string) (string, *externalFiles, error) {
+	data, err := newWidgetData(cfg, opts)
+	if err != nil {
+		return "", nil, err
+	}
+
+	tmpl, err := template.New("widget").Parse(widgetTemplate)
+	if err != nil {
+		return "", nil, err
+	}
+	execute := func(name string) (string, error) {
+		var buf stringBuilder
+		err := tmpl.ExecuteTemplate(&buf, name, data)
+		return buf.String(), err
+	}
+
+	var files *externalFiles
+	if opts.StrictCSP {
+		files = &externalFiles{ScriptName: scriptName}
+		if files.Style, err = execute("style"); err != nil {
+			return "", nil, err
+		}
+		if files.Script, err = execute("script"); err != nil {
+			return "", nil, err
+		}
+		files.StyleURL = opts.WidgetBaseURL + "widget.css"
+		files.StyleSRI = sriHash([]byte(files.Style))
+		files.ScriptURL = opts.WidgetBaseURL + scriptName
+		files.ScriptSRI = sriHash([]byte(files.Script))
+		data.External = files
+	}
+	body, err := execute("widget")
+	if err != nil {
+		return "", nil, err
+	}
+	return body, files, nil
+}
+
+// widgetData is what widgetTemplate renders. Scope is the selector every
+// style rule hangs off: "#<WidgetID>", or ":host" inside a Web Component.
+type widgetData struct {
+	WidgetID       string
+	Scope          string
+	ThemeVars      string
+	DarkThemeVars  string
+	RendererURL    string
+	RendererSRI    string
+	WorkerURL      string
+	WasmURL        string
+	AssetBase      string
+	AssetsJSON     string
+	Bundle         *widgetBundle
+	External       *externalFiles
+	Canvases       []*layoutCanvas
+	Description    string
+	PosterAlt      string
+	Panels         []layoutPanel
+	Readouts       []Readout
+	Presets        []Preset
+	Scenarios      []Scenario
+	GameConfigJSON string
+}
+
+// newWidgetData resolves cfg and opts into the template's data: the
+// layout, the runtime URLs (bundled, published or under RuntimeBaseURL)
+// and the game config JSON.
+func newWidgetData(cfg *Config, opts WidgetOptions) (*widgetData, error) {
+	panels, err := resolveLayout(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	rendererURL := opts.RuntimeBaseURL + "renderer.js"
+	workerURL := opts.RuntimeBaseURL + "worker.js"
+	assetsJSON := "null"
+	var bundle *widgetBundle
+	if opts.Bundle {
+		if bundle, err = loadBundle(cfg, opts); err != nil {
+			return nil, err
+		}
+		opts.WasmURL = bundle.WasmDataURL
+		assetsJSON = bundle.AssetsJSON
+	} else if p := opts.published; p != nil {
+		rendererURL, workerURL = p.Renderer, p.Worker
+		raw, err := json.Marshal(p.Assets)
+		if err != nil {
+			return nil, err
+		}
+		assetsJSON = string(raw)
+	}
 
 	// Marshal the renderer / sliders / readouts / driver as JSON so the
 	// widget script reads them as a plain object literal — same pattern
 	// the previous codegen used, just inlined now.
 	cfgJSON, err := marshalGameConfig(cfg)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	data := struct {
-		WidgetID       string
-		RuntimeBase    string
-		WasmURL        string
-		Description    string
-		CanvasWidth    int
-		CanvasHeight   int
-		Sliders        []Slider
-		Readouts       []Readout
-		HasControls    bool
-		ShowReset      bool
-		GameConfigJSON string
-	}{
-		WidgetID:       widgetID,
-		RuntimeBase:    runtimeBase,
-		WasmURL:        wasmURL,
-		Description:    cfg.Description,
-		CanvasWidth:    visConfig.CanvasWidth,
-		CanvasHeight:   visConfig.CanvasHeight,
-		Sliders:        cfg.Sliders,
-		Readouts:       cfg.Readouts,
-		HasControls:    hasControls,
-		ShowReset:      cfg.ShowReset,
-		GameConfigJSON: cfgJSON,
+	rendererSRI := ""
+	if opts.StrictCSP {
+		raw, err := os.ReadFile(filepath.Join(opts.RuntimeDir, "renderer.js"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read renderer.js for its integrity hash: %w", err)
+		}
+		rendererSRI = sriHash(raw)
 	}
 
-	tmpl, err := template.New("widget").Parse(widgetTemplate)
-	if err != nil {
-		return "", err
+	var canvases []*layoutCanvas
+	for _, p := range panels {
+		for _, g := range p.Groups {
+			for _, item := range g.Resolved {
+				if c := item.Canvas; c != nil {
+					c.MaxWidth = c.Width
+					if h := opts.MaxCanvasHeight; h > 0 && c.Height > h {
+						c.MaxWidth = c.Width * h / c.Height
+					}
+					canvases = append(canvases, c)
+				}
+			}
+		}
 	}
-	var buf stringBuilder
-	if err := tmpl.Execute(&buf, data); err != nil {
-		return "", err
+
+	if opts.Poster != nil {
+		if opts, err = PreparePoster(cfg, opts); err != nil {
+			return nil, err
+		}
+		renderPosters(cfg, opts, canvases)
+	}
+	posterAlt := cfg.Description
+	if posterAlt == "" {
+		posterAlt = cfg.Name
 	}
-	return buf.String(), nil
+
+	darkVars := ""
+	if opts.DarkTheme != nil {
+		darkVars = opts.DarkTheme.cssVars()
+	}
+
+	return &widgetData{
+		WidgetID:       opts.WidgetID,
+		Scope:          "#" + opts.WidgetID,
+		ThemeVars:      opts.Theme.cssVars(),
+		DarkThemeVars:  darkVars,
+		RendererURL:    rendererURL,
+		RendererSRI:    rendererSRI,
+		WorkerURL:      workerURL,
+		WasmURL:        opts.WasmURL,
+		AssetBase:      opts.AssetBaseURL,
+		AssetsJSON:     assetsJSON,
+		Bundle:         bundle,
+		Canvases:       canvases,
+		Description:    cfg.Description,
+		PosterAlt:      html.EscapeString(posterAlt),
+		Panels:         panels,
+		Readouts:       cfg.Readouts,
+		Presets:        cfg.Presets,
+		Scenarios:      cfg.Scenarios,
+		GameConfigJSON: cfgJSON,
+	}, nil
 }
 
 // stringBuilder is a tiny os.File-shaped wrapper around a strings.Builder
@@ -188,64 +490,110 @@ func (s *stringBuilder) String() string              { return string(s.b) }
 // widgetTemplate is the embeddable widget snippet. It's structured as
 // one wrapping <div id="{{.WidgetID}}">, an inline scoped <style>, the
 // panel layout, and an IIFE <script>. The script loads renderer.js from
-// RuntimeBase (deduplicating across widgets), then spins up its own
-// Worker pointing at RuntimeBase/worker.js with the gameConfig.driver.
-const widgetTemplate = `<div id="{{.WidgetID}}" class="dexetera-widget">
-<style>
-#{{.WidgetID}} { font-family: system-ui, -apple-system, sans-serif; color: #2c3e50; line-height: 1.5; }
-#{{.WidgetID}} .description { margin: 0 0 1em; color: #2c3e50; opacity: 0.85; font-size: 1rem; }
-#{{.WidgetID}} code { font-family: ui-monospace, SFMono-Regular, Menlo, monospace; font-size: 0.95em; background: rgba(60,120,216,0.08); padding: 0.05em 0.3em; border-radius: 3px; }
-#{{.WidgetID}} .dashboard { display: grid; grid-template-columns: repeat(auto-fit, minmax(320px, 1fr)); gap: 0.9em; }
-#{{.WidgetID}} .panel { border: 1px solid #2c3e50; border-radius: 6px; padding: 0.8em 0.9em; background: #ffffff; display: flex; flex-direction: column; gap: 0.6em; box-sizing: border-box; }
-#{{.WidgetID}} .panel-title { font-weight: 600; color: #2c3e50; font-size: 1rem; }
-#{{.WidgetID}} canvas { display: block; width: 100%; max-width: {{.CanvasWidth}}px; height: auto; aspect-ratio: {{.CanvasWidth}} / {{.CanvasHeight}}; margin: 0 auto; background: #ffffff; }
-#{{.WidgetID}} .panel-readout { margin: 0; font-size: 1rem; color: #2c3e50; font-family: ui-monospace, SFMono-Regular, Menlo, monospace; }
-#{{.WidgetID}} .slider { display: grid; grid-template-columns: 1fr 80px; grid-template-areas: "name readout" "input input"; align-items: center; gap: 0.3em 1em; font-size: 1rem; }
-#{{.WidgetID}} .slider-name { grid-area: name; color: #2c3e50; }
-#{{.WidgetID}} .slider input[type="range"] { grid-area: input; width: 100%; accent-color: #3c78d8; }
-#{{.WidgetID}} .slider-readout { grid-area: readout; text-align: right; color: #3c78d8; font-family: ui-monospace, SFMono-Regular, Menlo, monospace; }
-#{{.WidgetID}} .panel-actions { display: flex; flex-wrap: wrap; gap: 0.6em; margin-top: 0.4em; }
-#{{.WidgetID}} button.button-secondary { cursor: pointer; border: 1px solid #2c3e50; background: #ffffff; color: #2c3e50; padding: 0.4em 0.85em; border-radius: 6px; font-size: 1rem; font-family: inherit; }
-#{{.WidgetID}} button.button-secondary:hover { background: #f4f6f9; }
-#{{.WidgetID}} .status { margin: 1em 0 0; text-align: right; font-size: 0.9em; color: #2c3e50; opacity: 0.6; font-family: ui-monospace, SFMono-Regular, Menlo, monospace; }
-</style>
-{{if .Description}}<p class="description">{{.Description}}</p>{{end}}
+// RendererURL (deduplicating across widgets), then spins up its own
+// Worker from WorkerURL with the gameConfig.driver. Both default to files
+// under WidgetOptions.RuntimeBaseURL.
+const widgetTemplate = `{{define "style"}}{{.Scope}} { {{.ThemeVars}} }
+{{if .DarkThemeVars}}@media (prefers-color-scheme: dark) { {{.Scope}} { {{.DarkThemeVars}} } }
+{{end}}{{.Scope}} { display: block; font-family: var(--dexetera-font); color: var(--dexetera-text); line-height: 1.5; }
+{{.Scope}} .description { margin: 0 0 1em; color: var(--dexetera-text); opacity: 0.85; font-size: 1rem; }
+{{.Scope}} code { font-family: var(--dexetera-mono); font-size: 0.95em; background: var(--dexetera-subtle); padding: 0.05em 0.3em; border-radius: 3px; }
+{{.Scope}} .dashboard { display: grid; grid-template-columns: repeat(auto-fit, minmax(320px, 1fr)); gap: 0.9em; }
+{{.Scope}} .panel { border: 1px solid var(--dexetera-border); border-radius: var(--dexetera-radius); padding: 0.8em 0.9em; background: var(--dexetera-background); display: flex; flex-direction: column; gap: 0.6em; box-sizing: border-box; }
+{{.Scope}} .panel-title { font-weight: 600; color: var(--dexetera-text); font-size: 1rem; }
+{{.Scope}} .group { display: flex; flex-direction: column; gap: 0.6em; }
+{{.Scope}} .group-heading { font-weight: 600; color: var(--dexetera-text); opacity: 0.75; font-size: 0.9rem; }
+{{.Scope}} details.group > summary { cursor: pointer; }
+{{.Scope}} canvas { display: block; width: 100%; height: auto; margin: 0 auto; background: var(--dexetera-canvas); }
+{{range .Canvases}}{{$.Scope}} canvas[data-canvas="{{.Name}}"]{{if .Poster}}, {{$.Scope}} img[data-poster="{{.Name}}"]{{end}} { max-width: {{.MaxWidth}}px; aspect-ratio: {{.Width}} / {{.Height}};{{if .Background}} background: {{.Background}};{{end}} }
+{{if .Poster}}{{$.Scope}} img[data-poster="{{.Name}}"] { display: block; width: 100%; height: auto; margin: 0 auto; }
+{{$.Scope}} img[data-poster="{{.Name}}"] + canvas { display: none; }
+{{end}}{{end}}{{.Scope}} .panel-readout { margin: 0; font-size: 1rem; color: var(--dexetera-text); font-family: var(--dexetera-mono); }
+{{.Scope}} .slider { display: grid; grid-template-columns: 1fr 80px; grid-template-areas: "name readout" "input input"; align-items: center; gap: 0.3em 1em; font-size: 1rem; }
+{{.Scope}} .slider-name { grid-area: name; color: var(--dexetera-text); }
+{{.Scope}} .slider input[type="range"] { grid-area: input; width: 100%; accent-color: var(--dexetera-accent); }
+{{.Scope}} .toggle { display: flex; align-items: center; gap: 0.5em; font-size: 1rem; color: var(--dexetera-text); cursor: pointer; }
+{{.Scope}} .toggle input[type="checkbox"] { accent-color: var(--dexetera-accent); }
+{{.Scope}} .toggle input[type="checkbox"]:disabled { opacity: 0.5; }
+{{.Scope}} [hidden] { display: none !important; }
+{{.Scope}} .slider-readout { grid-area: readout; text-align: right; color: var(--dexetera-accent); font-family: var(--dexetera-mono); }
+{{.Scope}} .panel-actions { display: flex; flex-wrap: wrap; gap: 0.6em; margin-top: 0.4em; }
+{{.Scope}} .panel-presets { display: flex; flex-wrap: wrap; align-items: center; gap: 0.6em; }
+{{.Scope}} .panel-presets-label { color: var(--dexetera-text); opacity: 0.85; }
+{{.Scope}} button.button-secondary { cursor: pointer; border: 1px solid var(--dexetera-border); background: var(--dexetera-background); color: var(--dexetera-text); padding: 0.4em 0.85em; border-radius: var(--dexetera-radius); font-size: 1rem; font-family: inherit; }
+{{.Scope}} button.button-secondary:hover { background: var(--dexetera-subtle); }
+{{.Scope}} button.button-secondary:disabled { cursor: default; opacity: 0.5; background: var(--dexetera-background); }
+{{.Scope}} .slider input[type="range"]:disabled { opacity: 0.5; }
+{{.Scope}} .status { margin: 1em 0 0; text-align: right; font-size: 0.9em; color: var(--dexetera-text); opacity: 0.6; font-family: var(--dexetera-mono); }
+{{end}}{{define "markup"}}{{if .Description}}<p class="description">{{.Description}}</p>{{end}}
 <div class="dashboard">
+    {{range .Panels}}
     <section class="panel">
-        <div class="panel-title">Simulation</div>
-        <canvas width="{{.CanvasWidth}}" height="{{.CanvasHeight}}"></canvas>
-        {{range .Readouts}}
-        <p class="panel-readout" data-readout="{{.Partition}}">&nbsp;</p>
+        {{if .Title}}<div class="panel-title">{{.Title}}</div>{{end}}
+        {{range .Groups}}
+        {{if .Collapsible}}<details class="group"{{if not .Collapsed}} open{{end}}>
+        <summary class="group-heading">{{.Heading}}</summary>
+        {{else}}<div class="group">
+        {{if .Heading}}<div class="group-heading">{{.Heading}}</div>{{end}}
         {{end}}
-    </section>
-    {{if .HasControls}}
-    <section class="panel">
-        <div class="panel-title">Live controls</div>
-        {{range .Sliders}}
-        <label class="slider">
-            <span class="slider-name">{{.Label}}</span>
-            <input type="range" data-slider="{{.Name}}"
-                   min="{{.Min}}" max="{{.Max}}" step="{{.Step}}" value="{{.Default}}">
-            <span class="slider-readout" data-slider-readout="{{.Name}}">&nbsp;</span>
-        </label>
+        {{range .Resolved}}
+            {{if eq .Kind "canvas"}}{{with .Canvas}}
+            {{if .Poster}}<img data-poster="{{.Name}}" src="{{.Poster}}" width="{{.Width}}" height="{{.Height}}" alt="{{$.PosterAlt}}">{{end}}<canvas data-canvas="{{.Name}}" width="{{.Width}}" height="{{.Height}}"></canvas>
+            {{end}}
+            {{else if eq .Kind "readouts"}}
+            {{range $.Readouts}}
+            <p class="panel-readout" data-readout="{{.Partition}}">&nbsp;</p>
+            {{end}}
+            {{else if eq .Kind "toggle"}}{{with .Toggle}}
+            <label class="toggle" data-control="{{.Name}}">
+                <input type="checkbox" data-toggle="{{.Name}}"{{if .Default}} checked{{end}}>
+                <span class="toggle-name">{{.Label}}</span>
+            </label>
+            {{end}}{{else if eq .Kind "slider"}}{{with .Slider}}
+            <label class="slider" data-control="{{.Name}}">
+                <span class="slider-name">{{.Label}}</span>
+                <input type="range" data-slider="{{.Name}}"
+                       min="{{.Min}}" max="{{.Max}}" step="{{.Step}}" value="{{.Default}}">
+                <span class="slider-readout" data-slider-readout="{{.Name}}">&nbsp;</span>
+            </label>
+            {{end}}{{else if eq .Kind "presets"}}
+            <div class="panel-presets">
+                <span class="panel-presets-label">Presets:</span>
+                {{range $.Presets}}
+                <button type="button" class="button-secondary" data-preset="{{.Name}}">{{.Name}}</button>
+                {{end}}
+            </div>
+            {{else if eq .Kind "scenarios"}}
+            <div class="panel-actions">
+                {{range $.Scenarios}}
+                <button type="button" class="button-secondary" data-scenario="{{.Name}}">▶ Play scenario: {{.Name}}</button>
+                {{end}}
+            </div>
+            {{else if eq .Kind "reset"}}
+            <div class="panel-actions">
+                <button type="button" class="button-secondary" data-reset>Reset simulation</button>
+            </div>
+            {{end}}
         {{end}}
-        {{if .ShowReset}}
-        <div class="panel-actions">
-            <button type="button" class="button-secondary" data-reset>Reset simulation</button>
-        </div>
+        {{if .Collapsible}}</details>{{else}}</div>{{end}}
         {{end}}
     </section>
     {{end}}
 </div>
 <p class="status" data-status>Loading…</p>
-</div>
-<script>
-(function () {
+{{end}}{{define "script"}}(function () {
     var widget = document.getElementById('{{.WidgetID}}');
-    var RUNTIME_BASE = '{{.RuntimeBase}}';
+{{template "vars" .}}{{template "logic" .}}})();
+{{end}}{{define "vars"}}    var RENDERER_URL = '{{.RendererURL}}';
+    var RENDERER_SRI = '{{.RendererSRI}}';
+    var WORKER_URL = '{{.WorkerURL}}';
     var WASM_URL = '{{.WasmURL}}';
+    var WASM_GZIP = {{if .Bundle}}true{{else}}false{{end}};
+    var WORKER_SOURCE = {{if .Bundle}}{{.Bundle.WorkerSourceJSON}}{{else}}null{{end}};
+    var ASSETS = {{.AssetsJSON}};
+    var ASSET_BASE = '{{.AssetBase}}';
     var gameConfig = {{.GameConfigJSON}};
-
+{{end}}{{define "logic"}}
     // Load the renderer script lazily, sharing one promise across every
     // dexetera widget on the same page.
     function ensureRenderer() {
@@ -253,7 +601,11 @@ const widgetTemplate = `<div id="{{.WidgetID}}" class="dexetera-widget">
         if (self.__dexeteraLoading) return self.__dexeteraLoading;
         self.__dexeteraLoading = new Promise(function (resolve, reject) {
             var s = document.createElement('script');
-            s.src = RUNTIME_BASE + 'renderer.js';
+            s.src = RENDERER_URL;
+            if (RENDERER_SRI) {
+                s.integrity = RENDERER_SRI;
+                s.crossOrigin = 'anonymous';
+            }
             s.onload = function () { resolve(); };
             s.onerror = function () { reject(new Error('failed to load ' + s.src)); };
             document.head.appendChild(s);
@@ -264,49 +616,192 @@ const widgetTemplate = `<div id="{{.WidgetID}}" class="dexetera-widget">
     function $(sel) { return widget.querySelector(sel); }
     function $$(sel) { return widget.querySelectorAll(sel); }
 
+    // removePosters takes down the static posters, uncovering the live
+    // canvases beneath once the simulation has drawn its first frame.
+    var postersShown = true;
+    function removePosters() {
+        postersShown = false;
+        Array.prototype.forEach.call($$('img[data-poster]'), function (img) { img.remove(); });
+    }
+
     function setStatus(msg) {
         var el = $('[data-status]');
         if (el) el.textContent = msg;
     }
 
-    var slidersByPartition = (function () {
-        var grouped = {};
-        for (var i = 0; i < gameConfig.sliders.length; i++) {
-            var s = gameConfig.sliders[i];
-            if (!grouped[s.partition]) grouped[s.partition] = [];
-            grouped[s.partition].push(s);
-        }
-        return grouped;
-    })();
+    // Sliders and toggles are both "controls": each owns one slot of one
+    // partition's action vector, and can be named by presets, links,
+    // scenarios and conditions.
+    var controlsByName = {};
+    var controlsByPartition = {};
+    gameConfig.controls.forEach(function (c) {
+        controlsByName[c.name] = c;
+        if (!controlsByPartition[c.partition]) controlsByPartition[c.partition] = [];
+        controlsByPartition[c.partition].push(c);
+    });
+
+    var scenariosByName = {};
+    gameConfig.scenarios.forEach(function (sc) { scenariosByName[sc.name] = sc; });
 
     var worker = null;
 
-    function publishActions() {
-        for (var i = 0; i < gameConfig.sliders.length; i++) {
-            var s = gameConfig.sliders[i];
-            var input = $('[data-slider="' + s.name + '"]');
-            var ro = $('[data-slider-readout="' + s.name + '"]');
-            if (input && ro) ro.textContent = parseFloat(input.value).toFixed(s.decimals);
+    // Name of the scenario currently playing, or null. While one plays the
+    // manual controls are disabled and nothing is published, so the
+    // scripted action values aren't overwritten by the sliders.
+    var playingScenario = null;
+
+    function controlInput(name) {
+        return $('[data-slider="' + name + '"], [data-toggle="' + name + '"]');
+    }
+
+    // controlValue reads a control's live value: a toggle is 1 when
+    // checked and 0 otherwise.
+    function controlValue(c) {
+        var input = controlInput(c.name);
+        if (!input) return c.default;
+        return c.kind === 'toggle' ? (input.checked ? 1 : 0) : parseFloat(input.value);
+    }
+
+    function setControlValues(values) {
+        for (var name in values) {
+            if (!Object.prototype.hasOwnProperty.call(values, name)) continue;
+            var c = controlsByName[name];
+            var input = controlInput(name);
+            if (!c || !input) continue;
+            if (c.kind === 'toggle') input.checked = values[name] !== 0;
+            else input.value = values[name];
+        }
+    }
+
+    function conditionHolds(cond) {
+        if (!cond) return true;
+        var c = controlsByName[cond.control];
+        var v = c ? controlValue(c) : NaN;
+        switch (cond.op) {
+            case '!=': return v !== cond.value;
+            case '<': return v < cond.value;
+            case '<=': return v <= cond.value;
+            case '>': return v > cond.value;
+            case '>=': return v >= cond.value;
+            default: return v === cond.value;
         }
-        if (!worker) return;
+    }
+
+    // refreshControls brings every control's DOM in line with the current
+    // values: VisibleWhen/EnabledWhen conditions, the scenario lock, slider
+    // readouts and scenario button labels. Cheap enough to run on every
+    // input event.
+    function refreshControls() {
+        gameConfig.controls.forEach(function (c) {
+            var wrapper = $('[data-control="' + c.name + '"]');
+            var input = controlInput(c.name);
+            var ro = $('[data-slider-readout="' + c.name + '"]');
+            if (wrapper) wrapper.hidden = !conditionHolds(c.visibleWhen);
+            if (input) input.disabled = !!playingScenario || !conditionHolds(c.enabledWhen);
+            if (input && ro) ro.textContent = parseFloat(input.value).toFixed(c.decimals);
+        });
+        gameConfig.presets.forEach(function (p) {
+            var btn = $('[data-preset="' + p.name + '"]');
+            if (!btn) return;
+            btn.hidden = !conditionHolds(p.visibleWhen);
+            btn.disabled = !!playingScenario || !conditionHolds(p.enabledWhen);
+        });
+        gameConfig.scenarios.forEach(function (s) {
+            var btn = $('[data-scenario="' + s.name + '"]');
+            if (!btn) return;
+            var playing = s.name === playingScenario;
+            btn.hidden = !conditionHolds(s.visibleWhen);
+            btn.disabled = !playing && !conditionHolds(s.enabledWhen);
+            btn.textContent = (playing ? '■ Stop' : '▶ Play') + ' scenario: ' + s.name;
+        });
+    }
+
+    // publishActions sends every control's value, grouped into one
+    // zero-filled action vector per partition. Hidden and disabled
+    // controls are included, so the simulation always sees every slot.
+    function publishActions() {
+        refreshControls();
+        if (!worker || playingScenario) return;
         var partitions = {};
-        for (var partition in slidersByPartition) {
-            if (!Object.prototype.hasOwnProperty.call(slidersByPartition, partition)) continue;
-            var group = slidersByPartition[partition];
+        for (var partition in controlsByPartition) {
+            if (!Object.prototype.hasOwnProperty.call(controlsByPartition, partition)) continue;
+            var group = controlsByPartition[partition];
             var maxIdx = -1;
             for (var j = 0; j < group.length; j++) maxIdx = Math.max(maxIdx, group[j].valueIndex);
             var values = new Array(maxIdx + 1);
             for (var k = 0; k <= maxIdx; k++) values[k] = 0;
             for (var l = 0; l < group.length; l++) {
-                var sl = group[l];
-                var inp = $('[data-slider="' + sl.name + '"]');
-                values[sl.valueIndex] = parseFloat(inp ? inp.value : sl.default);
+                values[group[l].valueIndex] = controlValue(group[l]);
             }
             partitions[partition] = values;
         }
         worker.postMessage({ action: 'setActions', partitions: partitions });
     }
 
+    // applyPreset moves every control named in the preset to its value and
+    // republishes; presets marked reset also restart the worker, which
+    // publishes the new values itself once it has started.
+    function applyPreset(preset, renderer) {
+        if (playingScenario) return;
+        setControlValues(preset.values);
+        if (preset.reset) startWorker(renderer);
+        else publishActions();
+    }
+
+    // setScenarioState mirrors the wasm-side player: it disables manual
+    // input while a scenario plays, moves the controls to follow the
+    // events that have fired, and republishes them once playback ends.
+    function setScenarioState(name, applied) {
+        var wasPlaying = playingScenario;
+        playingScenario = name || null;
+        var sc = scenariosByName[name];
+        if (sc) {
+            for (var i = 0; i < applied && i < sc.events.length; i++) setControlValues(sc.events[i].values);
+        }
+        if (wasPlaying && !playingScenario) publishActions();
+        else refreshControls();
+    }
+
+    function toggleScenario(sc, renderer) {
+        if (playingScenario === sc.name) {
+            if (worker) worker.postMessage({ action: 'stopScenario' });
+        } else if (sc.reset) {
+            startWorker(renderer, sc.name);
+        } else if (worker) {
+            setScenarioState(sc.name, 0);
+            worker.postMessage({ action: 'playScenario', name: sc.name });
+        }
+    }
+
+    // bindControlLinks wires up links elsewhere on the page that follow the
+    // data-dexetera-target / data-set-<control> convention (see
+    // ControlLink). Attribute names arrive lowercased, so controls are
+    // looked up case-insensitively.
+    function bindControlLinks(renderer) {
+        var byLowerName = {};
+        gameConfig.controls.forEach(function (c) { byLowerName[c.name.toLowerCase()] = c.name; });
+        var links = document.querySelectorAll('[data-dexetera-target="' + (widget.host || widget).id + '"]');
+        Array.prototype.forEach.call(links, function (link) {
+            var values = {};
+            for (var j = 0; j < link.attributes.length; j++) {
+                var attr = link.attributes[j];
+                if (attr.name.indexOf('data-set-') !== 0) continue;
+                var name = byLowerName[attr.name.slice('data-set-'.length)];
+                var v = parseFloat(attr.value);
+                if (name === undefined || isNaN(v)) {
+                    console.warn('dexetera: ignoring ' + attr.name + '="' + attr.value + '" on control link');
+                    continue;
+                }
+                values[name] = v;
+            }
+            var preset = { values: values, reset: link.hasAttribute('data-reset') };
+            link.addEventListener('click', function (e) {
+                e.preventDefault();
+                applyPreset(preset, renderer);
+            });
+        });
+    }
+
     function applyReadout(template, decimals, partitionState) {
         var s = template;
         s = s.replace(/\{t\}/g, Math.floor(partitionState.timesteps));
@@ -318,20 +813,38 @@ const widgetTemplate = `<div id="{{.WidgetID}}" class="dexetera-widget">
         return s;
     }
 
-    function startWorker(renderer) {
+    // workerURL is WORKER_URL, or in a single-file bundle a Blob URL made
+    // once from the inlined worker source.
+    var workerBlobURL = null;
+    function workerURL() {
+        if (!WORKER_SOURCE) return WORKER_URL;
+        if (!workerBlobURL) {
+            workerBlobURL = URL.createObjectURL(new Blob([WORKER_SOURCE], { type: 'text/javascript' }));
+        }
+        return workerBlobURL;
+    }
+
+    // startWorker (re)launches the simulation, optionally playing the named
+    // scenario from the first step.
+    function startWorker(renderer, scenario) {
         if (worker) worker.terminate();
-        worker = new Worker(RUNTIME_BASE + 'worker.js');
+        worker = null;
+        setScenarioState(scenario || '', 0);
+        worker = new Worker(workerURL());
         worker.onmessage = function (e) {
             var msg = e.data;
             if (msg.type === 'partitionState') {
                 renderer.update(msg.data);
                 renderer.render();
+                if (postersShown) removePosters();
                 for (var i = 0; i < gameConfig.readouts.length; i++) {
                     var r = gameConfig.readouts[i];
                     if (r.partition !== msg.data.partitionName) continue;
                     var el = $('[data-readout="' + r.partition + '"]');
                     if (el) el.textContent = applyReadout(r.template, r.decimals, msg.data);
                 }
+            } else if (msg.type === 'scenario') {
+                setScenarioState(msg.data.name, msg.data.applied);
             } else if (msg.type === 'status') {
                 setStatus(msg.data);
             } else if (msg.type === 'error') {
@@ -346,20 +859,39 @@ const widgetTemplate = `<div id="{{.WidgetID}}" class="dexetera-widget">
         worker.postMessage({
             action: 'start',
             wasmBinary: new URL(WASM_URL, document.baseURI).href,
+            wasmGzip: WASM_GZIP,
             driver: gameConfig.driver,
+            scenario: scenario || null,
         });
         publishActions();
     }
 
     ensureRenderer().then(function () {
-        var canvas = $('canvas');
-        var renderer = self.dexetera.createRenderer(canvas, gameConfig.visualization);
+        // One renderer per canvas; every partition state is fanned out to
+        // all of them.
+        var renderers = [];
+        gameConfig.canvases.forEach(function (c) {
+            var canvas = $('canvas[data-canvas="' + c.name + '"]');
+            if (canvas) renderers.push(self.dexetera.createRenderer(canvas, c.visualization, { assetBase: ASSET_BASE, assets: ASSETS }));
+        });
+        var renderer = {
+            update: function (state) { renderers.forEach(function (r) { r.update(state); }); },
+            render: function () { renderers.forEach(function (r) { r.render(); }); },
+        };
 
-        for (var i = 0; i < gameConfig.sliders.length; i++) {
-            var s = gameConfig.sliders[i];
-            var el = $('[data-slider="' + s.name + '"]');
-            if (el) el.addEventListener('input', publishActions);
-        }
+        gameConfig.controls.forEach(function (c) {
+            var el = controlInput(c.name);
+            if (el) el.addEventListener(c.kind === 'toggle' ? 'change' : 'input', publishActions);
+        });
+        gameConfig.presets.forEach(function (p) {
+            var btn = $('[data-preset="' + p.name + '"]');
+            if (btn) btn.addEventListener('click', function () { applyPreset(p, renderer); });
+        });
+        gameConfig.scenarios.forEach(function (sc) {
+            var btn = $('[data-scenario="' + sc.name + '"]');
+            if (btn) btn.addEventListener('click', function () { toggleScenario(sc, renderer); });
+        });
+        bindControlLinks(renderer);
         if (gameConfig.showReset) {
             var btn = $('[data-reset]');
             if (btn) btn.addEventListener('click', function () { startWorker(renderer); });
@@ -370,14 +902,94 @@ const widgetTemplate = `<div id="{{.WidgetID}}" class="dexetera-widget">
         console.error(err);
         setStatus('Failed to load dexetera runtime: ' + err.message);
     });
+{{end}}{{define "component"}}// <dexetera-widget config="spec.json">: a dexetera widget inside a shadow
+// root, built from the JSON spec its config attribute points to. One copy
+// of this script serves every WebComponent widget on a page.
+(function () {
+if (!self.customElements || customElements.get('dexetera-widget')) return;
+
+function mount(widget, spec) {
+    var RENDERER_URL = spec.rendererURL;
+    var RENDERER_SRI = '';
+    var WORKER_URL = spec.workerURL;
+    var WASM_URL = spec.wasmURL;
+    var WASM_GZIP = false;
+    var WORKER_SOURCE = null;
+    var ASSETS = spec.assets;
+    var ASSET_BASE = spec.assetBase;
+    var gameConfig = spec.gameConfig;
+{{template "logic" .}}}
+
+customElements.define('dexetera-widget', class extends HTMLElement {
+    connectedCallback() {
+        if (this.shadowRoot) return;
+        var root = this.attachShadow({ mode: 'open' });
+        var src = new URL(this.getAttribute('config'), document.baseURI);
+        fetch(src).then(function (res) {
+            if (!res.ok) throw new Error(res.status + ' ' + res.statusText);
+            return res.json();
+        }).then(function (spec) {
+            root.innerHTML = '<style>' + spec.style + '</style>' + spec.markup;
+            mount(root, spec);
+        }).catch(function (err) {
+            console.error(err);
+            root.textContent = 'Failed to load ' + src + ': ' + err.message;
+        });
+    }
+});
+})();
+{{end}}{{if .External}}<link rel="stylesheet" href="{{.External.StyleURL}}" integrity="{{.External.StyleSRI}}" crossorigin="anonymous">
+<div id="{{.WidgetID}}" class="dexetera-widget">
+{{template "markup" .}}</div>
+<script src="{{.External.ScriptURL}}" integrity="{{.External.ScriptSRI}}" crossorigin="anonymous"></script>
+{{else}}<div id="{{.WidgetID}}" class="dexetera-widget">
+<style>
+{{template "style" .}}</style>
+{{template "markup" .}}</div>
+{{if .Bundle}}<script>
+if (!(self.dexetera && self.dexetera.createRenderer)) (function () {
+{{.Bundle.RendererSource}}
 })();
 </script>
-`
+{{end}}<script>
+{{template "script" .}}</script>
+{{end}}`
+
+// renderLinks produces links.html: one anchor per Config.Links entry,
+// following the data-attribute convention the widget script scans for at
+// load. Values are written in shortest round-trip form so the attribute
+// parses back to the same float64.
+func renderLinks(cfg *Config, widgetID string) string {
+	var b strings.Builder
+	b.WriteString("<!-- Control links for " + widgetID + ". Paste anywhere on the page that embeds the widget. -->\n")
+	for _, l := range cfg.Links {
+		b.WriteString(`<a href="#` + widgetID + `" data-dexetera-target="` + widgetID + `"`)
+		for _, name := range slices.Sorted(maps.Keys(l.Values)) {
+			b.WriteString(` data-set-` + strings.ToLower(name) + `="` +
+				strconv.FormatFloat(l.Values[name], 'g', -1, 64) + `"`)
+		}
+		if l.Reset {
+			b.WriteString(` data-reset`)
+		}
+		b.WriteString(`>` + html.EscapeString(l.Text) + "</a>\n")
+	}
+	return b.String()
+}
 
 // wrapTestHTML wraps a widget body in a minimal standalone HTML page so
 // that opening test.html in a browser (served via a static-file server)
-// previews the widget locally without any blog setup.
-func wrapTestHTML(name, widgetBody string) string {
+// previews the widget locally without any blog setup. Any control links
+// are placed after the widget, standing in for the surrounding prose.
+func wrapTestHTML(name, widgetBody, links string, dark *Theme) string {
+	if links != "" {
+		links = "<p class=\"links\">Control links:</p>\n" + links
+	}
+	darkPage := ""
+	if dark != nil {
+		d := dark.withDefaults()
+		darkPage = "@media (prefers-color-scheme: dark) { body { background: " + d.Canvas +
+			"; color: " + d.Text + "; } a[data-dexetera-target] { color: " + d.Accent + "; } }\n"
+	}
 	return `<!DOCTYPE html>
 <html lang="en">
 <head>
@@ -389,29 +1001,42 @@ body { margin: 0; padding: 2em 1.5em; max-width: 900px; margin: 0 auto;
        font-family: system-ui, -apple-system, sans-serif; color: #2c3e50; }
 h1 { font-size: 2.4em; font-weight: 600; letter-spacing: -0.02em; margin: 0 0 0.6em; }
 header p { margin: 0 0 1.4em; opacity: 0.65; font-size: 0.95em; }
-</style>
+p.links { margin: 1.4em 0 0.4em; opacity: 0.65; font-size: 0.95em; }
+a[data-dexetera-target] { margin-right: 1em; color: #3c78d8; }
+` + darkPage + `</style>
 </head>
 <body>
 <header>
 <h1>` + name + `</h1>
 <p>Local preview. The embeddable snippet is in widget.html.</p>
 </header>
-` + widgetBody + `
+` + widgetBody + links + `
 </body>
 </html>
 `
 }
 
 // jsConfig and friends are the JSON shape the inline widget script reads.
-// They mirror Slider / Readout / DriverSpec but with lowercase JSON tags
-// so the script can index them naturally as plain JS objects.
-
-type jsSlider struct {
-	Name       string  `json:"name"`
-	Partition  string  `json:"partition"`
-	ValueIndex int     `json:"valueIndex"`
-	Default    float64 `json:"default"`
-	Decimals   int     `json:"decimals"`
+// They mirror Slider / Toggle / Readout / DriverSpec etc. but with
+// lowercase JSON tags so the script can index them naturally as plain JS
+// objects.
+
+type jsCondition struct {
+	Control string  `json:"control"`
+	Op      string  `json:"op"`
+	Value   float64 `json:"value"`
+}
+
+// jsControl is a Slider or Toggle; Kind tells the script which.
+type jsControl struct {
+	Name        string       `json:"name"`
+	Kind        string       `json:"kind"`
+	Partition   string       `json:"partition"`
+	ValueIndex  int          `json:"valueIndex"`
+	Default     float64      `json:"default"`
+	Decimals    int          `json:"decimals"`
+	VisibleWhen *jsCondition `json:"visibleWhen"`
+	EnabledWhen *jsCondition `json:"enabledWhen"`
 }
 
 type jsReadout struct {
@@ -420,37 +1045,147 @@ type jsReadout struct {
 	Decimals  int    `json:"decimals"`
 }
 
-type jsConfig struct {
+type jsPreset struct {
+	Name        string             `json:"name"`
+	Values      map[string]float64 `json:"values"`
+	Reset       bool               `json:"reset"`
+	VisibleWhen *jsCondition       `json:"visibleWhen"`
+	EnabledWhen *jsCondition       `json:"enabledWhen"`
+}
+
+type jsScenarioEvent struct {
+	At     float64            `json:"at"`
+	Values map[string]float64 `json:"values"`
+}
+
+type jsScenario struct {
+	Name        string            `json:"name"`
+	Reset       bool              `json:"reset"`
+	Events      []jsScenarioEvent `json:"events"`
+	VisibleWhen *jsCondition      `json:"visibleWhen"`
+	EnabledWhen *jsCondition      `json:"enabledWhen"`
+}
+
+// jsCanvas is one canvas's renderer config; the primary canvas has an
+// empty name.
+type jsCanvas struct {
+	Name          string                 `json:"name"`
 	Visualization map[string]interface{} `json:"visualization"`
-	Sliders       []jsSlider             `json:"sliders"`
-	Readouts      []jsReadout            `json:"readouts"`
-	ShowReset     bool                   `json:"showReset"`
-	Driver        map[string]interface{} `json:"driver"`
 }
 
-func marshalGameConfig(cfg *Config) (string, error) {
-	visConfig := cfg.VisualizationConfig
+type jsConfig struct {
+	Canvases  []jsCanvas             `json:"canvases"`
+	Controls  []jsControl            `json:"controls"`
+	Readouts  []jsReadout            `json:"readouts"`
+	Presets   []jsPreset             `json:"presets"`
+	Scenarios []jsScenario           `json:"scenarios"`
+	ShowReset bool                   `json:"showReset"`
+	Driver    map[string]interface{} `json:"driver"`
+}
+
+// toJSVisualization converts a VisualizationConfig into the object
+// runtime/renderer.js expects.
+func toJSVisualization(visConfig *VisualizationConfig) map[string]interface{} {
 	renderers := make([]map[string]interface{}, 0, len(visConfig.Renderers))
 	for _, r := range visConfig.Renderers {
-		renderers = append(renderers, map[string]interface{}{
+		renderer := map[string]interface{}{
 			"type":          r.Type,
 			"partitionName": r.PartitionName,
 			"properties":    r.Properties,
-		})
+		}
+		if len(r.Bindings) > 0 {
+			renderer["bindings"] = toJSBindings(r)
+		}
+		renderers = append(renderers, renderer)
+	}
+	var world map[string]interface{}
+	if w := visConfig.World; w != nil {
+		world = map[string]interface{}{
+			"minX":       w.MinX,
+			"maxX":       w.MaxX,
+			"minY":       w.MinY,
+			"maxY":       w.MaxY,
+			"lockAspect": w.LockAspect,
+			"yUp":        w.YUp,
+		}
+	}
+	return map[string]interface{}{
+		"canvasWidth":      visConfig.CanvasWidth,
+		"canvasHeight":     visConfig.CanvasHeight,
+		"backgroundColor":  visConfig.BackgroundColor,
+		"updateIntervalMs": visConfig.UpdateIntervalMs,
+		"world":            world,
+		"renderers":        renderers,
+	}
+}
+
+// toJSBindings resolves a renderer's StateRef defaults (its own partition,
+// unit scale) so the runtime applies each binding as written.
+func toJSBindings(r RendererConfig) map[string]interface{} {
+	bindings := make(map[string]interface{}, len(r.Bindings))
+	for prop, ref := range r.Bindings {
+		partition := ref.Partition
+		if partition == "" {
+			partition = r.PartitionName
+		}
+		scale := ref.Scale
+		if scale == 0 {
+			scale = 1
+		}
+		b := map[string]interface{}{
+			"partition": partition,
+			"index":     ref.Index,
+			"scale":     scale,
+			"offset":    ref.Offset,
+		}
+		if ref.Template != "" {
+			b["template"] = ref.Template
+			b["decimals"] = ref.Decimals
+		}
+		bindings[prop] = b
 	}
+	return bindings
+}
 
-	sliders := make([]jsSlider, 0, len(cfg.Sliders))
+func marshalGameConfig(cfg *Config) (string, error) {
+	canvases := make([]jsCanvas, 0, len(cfg.Canvases)+1)
+	if cfg.VisualizationConfig != nil {
+		canvases = append(canvases, jsCanvas{Visualization: toJSVisualization(cfg.VisualizationConfig)})
+	}
+	for _, c := range cfg.Canvases {
+		canvases = append(canvases, jsCanvas{Name: c.Name, Visualization: toJSVisualization(c.Visualization)})
+	}
+
+	controls := make([]jsControl, 0, len(cfg.Sliders)+len(cfg.Toggles))
 	for _, s := range cfg.Sliders {
 		decimals := s.Decimals
 		if decimals == 0 {
 			decimals = 3
 		}
-		sliders = append(sliders, jsSlider{
-			Name:       s.Name,
-			Partition:  s.Partition,
-			ValueIndex: s.ValueIndex,
-			Default:    s.Default,
-			Decimals:   decimals,
+		controls = append(controls, jsControl{
+			Name:        s.Name,
+			Kind:        "slider",
+			Partition:   s.Partition,
+			ValueIndex:  s.ValueIndex,
+			Default:     s.Default,
+			Decimals:    decimals,
+			VisibleWhen: toJSCondition(s.VisibleWhen),
+			EnabledWhen: toJSCondition(s.EnabledWhen),
+		})
+	}
+	for _, t := range cfg.Toggles {
+		def := 0.0
+		if t.Default {
+			def = 1.0
+		}
+		controls = append(controls, jsControl{
+			Name:        t.Name,
+			Kind:        "toggle",
+			Partition:   t.Partition,
+			ValueIndex:  t.ValueIndex,
+			Default:     def,
+			VisibleWhen: toJSCondition(t.VisibleWhen),
+			EnabledWhen: toJSCondition(t.EnabledWhen),
 		})
 	}
 	readouts := make([]jsReadout, 0, len(cfg.Readouts))
@@ -466,21 +1201,53 @@ func marshalGameConfig(cfg *Config) (string, error) {
 		})
 	}
 
+	presets := make([]jsPreset, 0, len(cfg.Presets))
+	for _, p := range cfg.Presets {
+		values := p.Values
+		if values == nil {
+			values = map[string]float64{}
+		}
+		presets = append(presets, jsPreset{
+			Name:        p.Name,
+			Values:      values,
+			Reset:       p.Reset,
+			VisibleWhen: toJSCondition(p.VisibleWhen),
+			EnabledWhen: toJSCondition(p.EnabledWhen),
+		})
+	}
+
+	// Events are emitted in playback order: the worker reports progress
+	// as a count of applied events, which indexes into this list.
+	scenarios := make([]jsScenario, 0, len(cfg.Scenarios))
+	for _, sc := range cfg.Scenarios {
+		events := make([]jsScenarioEvent, 0, len(sc.Events))
+		for _, e := range sc.SortedEvents() {
+			values := e.Values
+			if values == nil {
+				values = map[string]float64{}
+			}
+			events = append(events, jsScenarioEvent{At: e.At, Values: values})
+		}
+		scenarios = append(scenarios, jsScenario{
+			Name:        sc.Name,
+			Reset:       sc.Reset,
+			Events:      events,
+			VisibleWhen: toJSCondition(sc.VisibleWhen),
+			EnabledWhen: toJSCondition(sc.EnabledWhen),
+		})
+	}
+
 	driverOpts := cfg.Driver.Options
 	if driverOpts == nil {
 		driverOpts = map[string]interface{}{}
 	}
 
 	jc := jsConfig{
-		Visualization: map[string]interface{}{
-			"canvasWidth":      visConfig.CanvasWidth,
-			"canvasHeight":     visConfig.CanvasHeight,
-			"backgroundColor":  visConfig.BackgroundColor,
-			"updateIntervalMs": visConfig.UpdateIntervalMs,
-			"renderers":        renderers,
-		},
-		Sliders:   sliders,
+		Canvases:  canvases,
+		Controls:  controls,
 		Readouts:  readouts,
+		Presets:   presets,
+		Scenarios: scenarios,
 		ShowReset: cfg.ShowReset,
 		Driver: map[string]interface{}{
 			"kind":    cfg.Driver.Kind,
@@ -494,6 +1261,19 @@ func marshalGameConfig(cfg *Config) (string, error) {
 	return string(out), nil
 }
 
+// toJSCondition converts a Condition for the widget script, filling in
+// the default "==" operator. Nil stays nil (serialised as null: always).
+func toJSCondition(c *Condition) *jsCondition {
+	if c == nil {
+		return nil
+	}
+	op := c.Op
+	if op == "" {
+		op = "=="
+	}
+	return &jsCondition{Control: c.Control, Op: op, Value: c.Value}
+}
+
 // generateBuildScript writes a per-widget build.sh that compiles the
 // example's wasm into <outputDir>/src/main.wasm. Same shape as before.
 func generateBuildScript(outputDir, name string) error {
@@ -0,0 +1,259 @@
+package dashboard
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Reserved layout item names. Anything else in ControlGroup.Items is the
+// name of a Slider or Toggle. Control names may not start with "@".
+const (
+	// LayoutCanvas places the primary visualization canvas
+	// (Config.VisualizationConfig). See LayoutCanvasNamed for the others.
+	LayoutCanvas = "@canvas"
+	// LayoutReadouts places every Readout, in declaration order.
+	LayoutReadouts = "@readouts"
+	// LayoutPresets places the row of preset buttons.
+	LayoutPresets = "@presets"
+	// LayoutScenarios places the row of "play scenario" buttons.
+	LayoutScenarios = "@scenarios"
+	// LayoutReset places the "Reset simulation" button, which the Config
+	// must enable with ShowReset.
+	LayoutReset = "@reset"
+)
+
+// LayoutCanvasNamed is the layout item that places the named canvas from
+// Config.Canvases.
+func LayoutCanvasNamed(name string) string {
+	return LayoutCanvas + ":" + name
+}
+
+// Layout arranges a widget's contents into a grid of panels. Panels are
+// laid out in order (wrapping onto new rows as the host page narrows),
+// each panel holds an ordered list of groups, and each group an ordered
+// list of items.
+//
+// A Config without a Layout gets the default: a "Simulation" panel with
+// the primary canvas and readouts, one panel per named canvas, then a
+// "Live controls" panel with toggles, sliders, presets, scenarios and the
+// reset button. Every canvas must be placed somewhere. Items a custom Layout
+// leaves out are not rendered; sliders and toggles left out still publish
+// their Default to the simulation.
+type Layout struct {
+	Panels []Panel
+}
+
+// Panel is one bordered box in the widget's panel grid.
+type Panel struct {
+	Title  string
+	Groups []ControlGroup
+}
+
+// ControlGroup is a run of items within a panel, optionally under a
+// heading. A Collapsible group renders as a <details> element, closed on
+// load if Collapsed is set — the usual home for "advanced" controls.
+type ControlGroup struct {
+	Heading     string
+	Items       []string
+	Collapsible bool
+	Collapsed   bool
+}
+
+// LayoutBuilder is a fluent helper for assembling a Layout. Group methods
+// append to the most recently started panel.
+type LayoutBuilder struct {
+	layout *Layout
+}
+
+// NewLayoutBuilder returns a LayoutBuilder with no panels.
+func NewLayoutBuilder() *LayoutBuilder {
+	return &LayoutBuilder{layout: &Layout{}}
+}
+
+// Panel starts a new panel with the given title.
+func (lb *LayoutBuilder) Panel(title string) *LayoutBuilder {
+	lb.layout.Panels = append(lb.layout.Panels, Panel{Title: title})
+	return lb
+}
+
+// Group appends an always-open group of items to the current panel. An
+// empty heading renders the items without one.
+func (lb *LayoutBuilder) Group(heading string, items ...string) *LayoutBuilder {
+	return lb.appendGroup(ControlGroup{Heading: heading, Items: items})
+}
+
+// CollapsibleGroup appends a group that the reader can fold away, closed
+// on load when collapsed is true.
+func (lb *LayoutBuilder) CollapsibleGroup(heading string, collapsed bool, items ...string) *LayoutBuilder {
+	return lb.appendGroup(ControlGroup{
+		Heading: heading, Items: items, Collapsible: true, Collapsed: collapsed,
+	})
+}
+
+func (lb *LayoutBuilder) appendGroup(g ControlGroup) *LayoutBuilder {
+	if len(lb.layout.Panels) == 0 {
+		lb.layout.Panels = append(lb.layout.Panels, Panel{})
+	}
+	last := &lb.layout.Panels[len(lb.layout.Panels)-1]
+	last.Groups = append(last.Groups, g)
+	return lb
+}
+
+// Build returns the assembled Layout.
+func (lb *LayoutBuilder) Build() *Layout {
+	return lb.layout
+}
+
+// defaultLayout reproduces the two-panel arrangement used when a Config
+// doesn't declare its own Layout.
+func defaultLayout(cfg *Config) *Layout {
+	lb := NewLayoutBuilder()
+	readoutsPlaced := false
+	if cfg.VisualizationConfig != nil {
+		lb.Panel("Simulation").Group("", LayoutCanvas, LayoutReadouts)
+		readoutsPlaced = true
+	}
+	for _, c := range cfg.Canvases {
+		items := []string{LayoutCanvasNamed(c.Name)}
+		if !readoutsPlaced {
+			items = append(items, LayoutReadouts)
+			readoutsPlaced = true
+		}
+		lb.Panel(c.Name).Group("", items...)
+	}
+
+	var controls []string
+	for _, t := range cfg.Toggles {
+		controls = append(controls, t.Name)
+	}
+	for _, s := range cfg.Sliders {
+		controls = append(controls, s.Name)
+	}
+	if len(cfg.Presets) > 0 {
+		controls = append(controls, LayoutPresets)
+	}
+	if len(cfg.Scenarios) > 0 {
+		controls = append(controls, LayoutScenarios)
+	}
+	if cfg.ShowReset {
+		controls = append(controls, LayoutReset)
+	}
+	if len(controls) > 0 {
+		lb.Panel("Live controls").Group("", controls...)
+	}
+	return lb.Build()
+}
+
+// layoutItem is one resolved entry of a ControlGroup: Kind is "canvas",
+// "readouts", "presets", "scenarios", "reset", "slider" or "toggle", and
+// exactly the field matching Kind (if any) is set.
+type layoutItem struct {
+	Kind   string
+	Canvas *layoutCanvas
+	Slider *Slider
+	Toggle *Toggle
+}
+
+// layoutCanvas is what the template needs to draw one <canvas>. Name is
+// empty for the primary canvas. MaxWidth is the widest it's displayed, in
+// CSS pixels, and Poster the data: URL of its poster, if any.
+type layoutCanvas struct {
+	Name       string
+	Width      int
+	Height     int
+	MaxWidth   int
+	Background string
+	Poster     string
+}
+
+type layoutGroup struct {
+	ControlGroup
+	Resolved []layoutItem
+}
+
+type layoutPanel struct {
+	Title  string
+	Groups []layoutGroup
+}
+
+// resolveLayout maps cfg's Layout (or the default) onto the items the
+// widget template renders, checking that every item name refers to
+// something that exists, that nothing is placed twice, and that the
+// canvas is placed exactly once.
+func resolveLayout(cfg *Config) ([]layoutPanel, error) {
+	layout := cfg.Layout
+	if layout == nil {
+		layout = defaultLayout(cfg)
+	}
+
+	sliders := make(map[string]*Slider, len(cfg.Sliders))
+	for i := range cfg.Sliders {
+		sliders[cfg.Sliders[i].Name] = &cfg.Sliders[i]
+	}
+	toggles := make(map[string]*Toggle, len(cfg.Toggles))
+	for i := range cfg.Toggles {
+		toggles[cfg.Toggles[i].Name] = &cfg.Toggles[i]
+	}
+	canvases := make(map[string]*layoutCanvas, len(cfg.Canvases)+1)
+	if v := cfg.VisualizationConfig; v != nil {
+		canvases[LayoutCanvas] = &layoutCanvas{
+			Width: v.CanvasWidth, Height: v.CanvasHeight, Background: cssColor(v.BackgroundColor),
+		}
+	}
+	for _, c := range cfg.Canvases {
+		if v := c.Visualization; v != nil {
+			canvases[LayoutCanvasNamed(c.Name)] = &layoutCanvas{
+				Name: c.Name, Width: v.CanvasWidth, Height: v.CanvasHeight, Background: cssColor(v.BackgroundColor),
+			}
+		}
+	}
+	reserved := map[string]string{
+		LayoutReadouts:  "readouts",
+		LayoutPresets:   "presets",
+		LayoutScenarios: "scenarios",
+		LayoutReset:     "reset",
+	}
+
+	placed := make(map[string]struct{})
+	panels := make([]layoutPanel, 0, len(layout.Panels))
+	for _, p := range layout.Panels {
+		panel := layoutPanel{Title: p.Title}
+		for _, g := range p.Groups {
+			group := layoutGroup{ControlGroup: g}
+			for _, name := range g.Items {
+				if _, dup := placed[name]; dup {
+					return nil, fmt.Errorf("layout places %q more than once", name)
+				}
+				placed[name] = struct{}{}
+				var item layoutItem
+				if c, ok := canvases[name]; ok {
+					item = layoutItem{Kind: "canvas", Canvas: c}
+				} else if kind, ok := reserved[name]; ok {
+					if name == LayoutReset && !cfg.ShowReset {
+						return nil, fmt.Errorf("layout places %q but the config doesn't enable ShowReset", name)
+					}
+					item = layoutItem{Kind: kind}
+				} else if s, ok := sliders[name]; ok {
+					item = layoutItem{Kind: "slider", Slider: s}
+				} else if t, ok := toggles[name]; ok {
+					item = layoutItem{Kind: "toggle", Toggle: t}
+				} else if strings.HasPrefix(name, "@") {
+					return nil, fmt.Errorf("layout: unknown reserved item %q", name)
+				} else {
+					return nil, fmt.Errorf("layout: unknown control %q", name)
+				}
+				group.Resolved = append(group.Resolved, item)
+			}
+			panel.Groups = append(panel.Groups, group)
+		}
+		panels = append(panels, panel)
+	}
+	for _, name := range slices.Sorted(maps.Keys(canvases)) {
+		if _, ok := placed[name]; !ok {
+			return nil, fmt.Errorf("layout does not place the canvas (%s)", name)
+		}
+	}
+	return panels, nil
+}
@@ -0,0 +1,185 @@
+package dashboard_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/umbralcalc/dexetera/pkg/dashboard"
+)
+
+func TestValidate_Layout(t *testing.T) {
+	t.Run("custom panels and groups pass", func(t *testing.T) {
+		cfg := baseBuilder().
+			WithResetButton().
+			WithLayout(dashboard.NewLayoutBuilder().
+				Panel("Simulation").Group("", dashboard.LayoutCanvas, dashboard.LayoutReadouts).
+				Panel("Controls").Group("Rates", "r").
+				CollapsibleGroup("Advanced", true, "K", dashboard.LayoutReset).
+				Build()).
+			Build()
+		if err := cfg.Validate(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("unknown control is rejected", func(t *testing.T) {
+		cfg := baseBuilder().
+			WithLayout(dashboard.NewLayoutBuilder().
+				Panel("").Group("", dashboard.LayoutCanvas, "q").
+				Build()).
+			Build()
+		expectError(t, cfg.Validate(), `layout: unknown control "q"`)
+	})
+
+	t.Run("control placed twice is rejected", func(t *testing.T) {
+		cfg := baseBuilder().
+			WithLayout(dashboard.NewLayoutBuilder().
+				Panel("").Group("", dashboard.LayoutCanvas, "r").
+				Panel("").Group("", "r").
+				Build()).
+			Build()
+		expectError(t, cfg.Validate(), `layout places "r" more than once`)
+	})
+
+	t.Run("missing canvas is rejected", func(t *testing.T) {
+		cfg := baseBuilder().
+			WithLayout(dashboard.NewLayoutBuilder().Panel("").Group("", "r").Build()).
+			Build()
+		expectError(t, cfg.Validate(), "does not place the canvas")
+	})
+
+	t.Run("reset button without ShowReset is rejected", func(t *testing.T) {
+		cfg := baseBuilder().
+			WithLayout(dashboard.NewLayoutBuilder().
+				Panel("").Group("", dashboard.LayoutCanvas, dashboard.LayoutReset).
+				Build()).
+			Build()
+		expectError(t, cfg.Validate(), "doesn't enable ShowReset")
+	})
+
+	t.Run("reserved control names are rejected", func(t *testing.T) {
+		cfg := baseBuilder().
+			WithSlider(dashboard.Slider{Name: "@canvas", Partition: "p", ValueIndex: 2}).
+			Build()
+		expectError(t, cfg.Validate(), "reserved for layout items")
+	})
+}
+
+func TestGenerateWidget_Layout(t *testing.T) {
+	cfg := baseBuilder().
+		WithLayout(dashboard.NewLayoutBuilder().
+			Panel("Simulation").Group("", dashboard.LayoutCanvas).
+			Panel("Growth").Group("Rates", "r").
+			CollapsibleGroup("Advanced", true, "K").
+			Build()).
+		Build()
+	dir := t.TempDir()
+	if err := dashboard.GenerateWidget(cfg, dashboard.WidgetOptions{OutputDir: dir}); err != nil {
+		t.Fatalf("GenerateWidget: %v", err)
+	}
+	raw, err := os.ReadFile(filepath.Join(dir, "widget.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	html := string(raw)
+	for _, want := range []string{
+		`<div class="panel-title">Growth</div>`,
+		`<div class="group-heading">Rates</div>`,
+		`<details class="group">`,
+		`<summary class="group-heading">Advanced</summary>`,
+		`data-slider="K"`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("widget.html is missing %q", want)
+		}
+	}
+	if strings.Contains(html, "Live controls") {
+		t.Errorf("custom layout should replace the default panels")
+	}
+}
+
+func TestValidate_Canvases(t *testing.T) {
+	chart := dashboard.NewVisualizationBuilder().WithCanvas(300, 150).Build()
+
+	t.Run("named canvases pass and get default panels", func(t *testing.T) {
+		cfg := baseBuilder().WithNamedVisualization("chart", chart).Build()
+		if err := cfg.Validate(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("named canvas alone is enough", func(t *testing.T) {
+		cfg := dashboard.NewConfigBuilder("test").
+			WithNamedVisualization("chart", chart).
+			Build()
+		if err := cfg.Validate(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("duplicate canvas name is rejected", func(t *testing.T) {
+		cfg := baseBuilder().
+			WithNamedVisualization("chart", chart).
+			WithNamedVisualization("chart", chart).
+			Build()
+		expectError(t, cfg.Validate(), `duplicate canvas name "chart"`)
+	})
+
+	t.Run("unplaced canvas is rejected", func(t *testing.T) {
+		cfg := baseBuilder().
+			WithNamedVisualization("chart", chart).
+			WithLayout(dashboard.NewLayoutBuilder().Panel("").Group("", dashboard.LayoutCanvas).Build()).
+			Build()
+		expectError(t, cfg.Validate(), "does not place the canvas (@canvas:chart)")
+	})
+}
+
+func TestGenerateWidget_Canvases(t *testing.T) {
+	cfg := baseBuilder().
+		WithNamedVisualization("chart", dashboard.NewVisualizationBuilder().WithCanvas(300, 150).Build()).
+		Build()
+	dir := t.TempDir()
+	if err := dashboard.GenerateWidget(cfg, dashboard.WidgetOptions{OutputDir: dir}); err != nil {
+		t.Fatalf("GenerateWidget: %v", err)
+	}
+	raw, err := os.ReadFile(filepath.Join(dir, "widget.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	html := string(raw)
+	for _, want := range []string{
+		`<canvas data-canvas="" width="400" height="200"`,
+		`<div class="panel-title">chart</div>`,
+		`<canvas data-canvas="chart" width="300" height="150"`,
+		`"canvases":[{"name":""`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("widget.html is missing %q", want)
+		}
+	}
+}
+
+func TestValidate_World(t *testing.T) {
+	t.Run("valid world passes", func(t *testing.T) {
+		cfg := baseBuilder().
+			WithNamedVisualization("field", dashboard.NewVisualizationBuilder().
+				WithWorld(dashboard.WorldCoordinates{MinX: -1, MaxX: 1, MinY: -1, MaxY: 1, YUp: true}).
+				AddCircle("", 0, 0, 0.1, nil).
+				Build()).
+			Build()
+		if err := cfg.Validate(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("empty range is rejected", func(t *testing.T) {
+		cfg := baseBuilder().
+			WithNamedVisualization("field", dashboard.NewVisualizationBuilder().
+				WithWorld(dashboard.WorldCoordinates{MaxX: 1}).
+				Build()).
+			Build()
+		expectError(t, cfg.Validate(), `canvas "field": world range`)
+	})
+}
@@ -0,0 +1,111 @@
+package dashboard
+
+import "math"
+
+// NetworkEdge is a link between two nodes of an AddNetwork renderer, by
+// node index.
+type NetworkEdge struct {
+	From int
+	To   int
+}
+
+// CircleLayout places n nodes evenly around a circle, starting at the
+// top and going clockwise. Positions are in the unit square, as
+// NetworkOptions.Positions expects.
+func CircleLayout(n int) [][2]float64 {
+	positions := make([][2]float64, n)
+	for i := range positions {
+		angle := 2*math.Pi*float64(i)/float64(n) - math.Pi/2
+		positions[i] = [2]float64{0.5 + 0.5*math.Cos(angle), 0.5 + 0.5*math.Sin(angle)}
+	}
+	return positions
+}
+
+// ForceLayout computes node positions with a Fruchterman–Reingold force
+// simulation: every pair of nodes repels, every edge attracts, and the
+// step size cools over the given number of iterations (200 if zero). It
+// starts from CircleLayout, so the result is deterministic. The positions
+// are rescaled to fill the unit square.
+//
+// The cost is O(iterations × n²), which is fine at generate time for the
+// few hundred nodes a readable network diagram has.
+func ForceLayout(n int, edges []NetworkEdge, iterations int) [][2]float64 {
+	positions := CircleLayout(n)
+	if n < 2 {
+		return positions
+	}
+	if iterations <= 0 {
+		iterations = 200
+	}
+	k := math.Sqrt(1 / float64(n))
+	disp := make([][2]float64, n)
+	for it := 0; it < iterations; it++ {
+		for i := range disp {
+			disp[i] = [2]float64{}
+		}
+		for i := 0; i < n; i++ {
+			for j := i + 1; j < n; j++ {
+				dx, dy, d := separation(positions[i], positions[j])
+				f := k * k / d
+				disp[i][0] += dx / d * f
+				disp[i][1] += dy / d * f
+				disp[j][0] -= dx / d * f
+				disp[j][1] -= dy / d * f
+			}
+		}
+		for _, e := range edges {
+			if e.From < 0 || e.From >= n || e.To < 0 || e.To >= n || e.From == e.To {
+				continue
+			}
+			dx, dy, d := separation(positions[e.From], positions[e.To])
+			f := d * d / k
+			disp[e.From][0] -= dx / d * f
+			disp[e.From][1] -= dy / d * f
+			disp[e.To][0] += dx / d * f
+			disp[e.To][1] += dy / d * f
+		}
+		temperature := 0.1 * (1 - float64(it)/float64(iterations))
+		for i := range positions {
+			length := math.Hypot(disp[i][0], disp[i][1])
+			if length == 0 {
+				continue
+			}
+			step := math.Min(length, temperature)
+			positions[i][0] += disp[i][0] / length * step
+			positions[i][1] += disp[i][1] / length * step
+		}
+	}
+	return normalizePositions(positions)
+}
+
+// separation returns the vector from b to a and its length, nudging
+// coincident nodes apart so forces stay finite.
+func separation(a, b [2]float64) (dx, dy, d float64) {
+	dx, dy = a[0]-b[0], a[1]-b[1]
+	d = math.Hypot(dx, dy)
+	if d < 1e-6 {
+		return 1e-6, 0, 1e-6
+	}
+	return dx, dy, d
+}
+
+// normalizePositions rescales positions to span the unit square,
+// preserving the aspect ratio and centring the shorter side.
+func normalizePositions(positions [][2]float64) [][2]float64 {
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, p := range positions {
+		minX, maxX = math.Min(minX, p[0]), math.Max(maxX, p[0])
+		minY, maxY = math.Min(minY, p[1]), math.Max(maxY, p[1])
+	}
+	span := math.Max(maxX-minX, maxY-minY)
+	if span == 0 {
+		return positions
+	}
+	offX := (span - (maxX - minX)) / 2
+	offY := (span - (maxY - minY)) / 2
+	for i, p := range positions {
+		positions[i] = [2]float64{(p[0] - minX + offX) / span, (p[1] - minY + offY) / span}
+	}
+	return positions
+}
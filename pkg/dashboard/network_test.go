@@ -0,0 +1,65 @@
+package dashboard_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/umbralcalc/dexetera/pkg/dashboard"
+)
+
+func TestForceLayout(t *testing.T) {
+	// Two triangles joined by a single bridge edge (2-3).
+	edges := []dashboard.NetworkEdge{
+		{From: 0, To: 1}, {From: 1, To: 2}, {From: 2, To: 0},
+		{From: 3, To: 4}, {From: 4, To: 5}, {From: 5, To: 3},
+		{From: 2, To: 3},
+	}
+	positions := dashboard.ForceLayout(6, edges, 0)
+
+	t.Run("positions fill the unit square", func(t *testing.T) {
+		for i, p := range positions {
+			if p[0] < 0 || p[0] > 1 || p[1] < 0 || p[1] > 1 {
+				t.Errorf("node %d at %v is outside the unit square", i, p)
+			}
+		}
+	})
+
+	t.Run("clusters end up apart", func(t *testing.T) {
+		dist := func(a, b int) float64 {
+			return math.Hypot(positions[a][0]-positions[b][0], positions[a][1]-positions[b][1])
+		}
+		if within, across := dist(0, 1), dist(0, 4); within >= across {
+			t.Errorf("expected 0-1 (%.3f) closer than 0-4 (%.3f)", within, across)
+		}
+	})
+
+	t.Run("layout is deterministic", func(t *testing.T) {
+		again := dashboard.ForceLayout(6, edges, 0)
+		for i := range positions {
+			if positions[i] != again[i] {
+				t.Fatalf("node %d moved between runs: %v vs %v", i, positions[i], again[i])
+			}
+		}
+	})
+}
+
+func TestAddNetwork(t *testing.T) {
+	edges := []dashboard.NetworkEdge{{From: 0, To: 1}, {From: 1, To: 2}}
+	vis := dashboard.NewVisualizationBuilder().
+		AddNetwork("infected", 3, edges, 0, 0, 200, 200, &dashboard.NetworkOptions{
+			ColorChannel:    true,
+			WeightPartition: "contacts",
+		}).
+		Build()
+
+	props := vis.Renderers[0].Properties
+	if positions, ok := props["positions"].([][2]float64); !ok || len(positions) != 3 {
+		t.Fatalf("expected a default circle layout of 3 nodes, got %#v", props["positions"])
+	}
+	if edgeList, ok := props["edges"].([][2]int); !ok || edgeList[1] != [2]int{1, 2} {
+		t.Errorf("unexpected edges %#v", props["edges"])
+	}
+	if props["weightPartition"] != "contacts" || props["colorChannel"] != true {
+		t.Errorf("unexpected properties %#v", props)
+	}
+}
@@ -0,0 +1,707 @@
+package dashboard
+
+import (
+	"encoding/base64"
+	"fmt"
+	"html"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/umbralcalc/stochadex/pkg/simulator"
+)
+
+// PosterOptions configures the static poster a widget shows in place of
+// each canvas until its simulation draws the first frame, and for good
+// where scripts never run: with JavaScript disabled, or in a feed reader.
+//
+// The poster is drawn in Go from a native run of the Config's simulation,
+// with every control at its default, as an SVG embedded in the snippet.
+// Text, shapes, line charts, bar charts, progress bars, scatter plots
+// and point sets are drawn; axis ticks and the other renderers are left
+// out.
+type PosterOptions struct {
+	// Steps is how many steps of the simulation to run before drawing.
+	// Defaults to 100, the samples a line chart shows by default.
+	Steps int
+}
+
+// posterSample is one output of a partition during the poster run.
+type posterSample struct {
+	time   float64
+	values []float64
+}
+
+// posterHistory collects every output of the poster run by partition.
+type posterHistory struct {
+	mu      sync.Mutex
+	samples map[string][]posterSample
+}
+
+func (h *posterHistory) Configure(*simulator.Settings) {}
+
+func (h *posterHistory) Output(partitionName string, state []float64, cumulativeTimesteps float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.samples[partitionName] = append(h.samples[partitionName],
+		posterSample{time: cumulativeTimesteps, values: append([]float64(nil), state...)})
+}
+
+// controlDefaults returns the action vector each partition's controls
+// publish before the reader touches them, built as the widget builds it.
+func controlDefaults(cfg *Config) map[string][]float64 {
+	defaults := make(map[string][]float64)
+	set := func(partition string, index int, value float64) {
+		values := defaults[partition]
+		for len(values) <= index {
+			values = append(values, 0)
+		}
+		values[index] = value
+		defaults[partition] = values
+	}
+	for _, s := range cfg.Sliders {
+		set(s.Partition, s.ValueIndex, s.Default)
+	}
+	for _, t := range cfg.Toggles {
+		v := 0.0
+		if t.Default {
+			v = 1
+		}
+		set(t.Partition, t.ValueIndex, v)
+	}
+	return defaults
+}
+
+// PreparePoster runs cfg's simulation for the poster opts asks for, if
+// any, and returns opts carrying the run. Every widget rendered from the
+// returned opts draws its posters from that one run, so a stochastic
+// model's posters agree across them; GenerateWidget and RenderWidget
+// call it themselves. A panic in the simulation is returned as an error.
+func PreparePoster(cfg *Config, opts WidgetOptions) (WidgetOptions, error) {
+	if opts.Poster == nil || opts.posterRun != nil {
+		return opts, nil
+	}
+	steps := opts.Poster.Steps
+	if steps <= 0 {
+		steps = 100
+	}
+	history, err := runPoster(cfg, steps)
+	if err != nil {
+		return opts, fmt.Errorf("poster simulation for %q failed: %w", cfg.Name, err)
+	}
+	opts.posterRun = history
+	return opts, nil
+}
+
+// runPoster runs cfg's simulation natively for steps steps with the
+// controls at their defaults, returning every partition's outputs.
+func runPoster(cfg *Config, steps int) (samples map[string][]posterSample, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	settings, implementations := prepareSimulationRun(cfg, controlDefaults(cfg))
+	history := &posterHistory{samples: make(map[string][]posterSample)}
+	implementations.OutputCondition = &simulator.EveryStepOutputCondition{}
+	implementations.OutputFunction = history
+	implementations.TerminationCondition = &simulator.NumberOfStepsTerminationCondition{MaxNumberOfSteps: steps}
+	simulator.NewPartitionCoordinator(settings, implementations).Run()
+	return history.samples, nil
+}
+
+// renderPosters sets the Poster of every canvas to a data: URL of its
+// SVG, drawn from the run PreparePoster stored in opts.
+func renderPosters(cfg *Config, opts WidgetOptions, canvases []*layoutCanvas) {
+	theme := opts.Theme.withDefaults()
+	for _, c := range canvases {
+		vis := cfg.VisualizationConfig
+		for _, named := range cfg.Canvases {
+			if c.Name != "" && named.Name == c.Name {
+				vis = named.Visualization
+			}
+		}
+		svg := renderPosterSVG(vis, opts.posterRun, theme)
+		c.Poster = "data:image/svg+xml;base64," + base64.StdEncoding.EncodeToString([]byte(svg))
+	}
+}
+
+// renderPosterSVG draws vis as runtime/renderer.js would after receiving
+// history, following its defaults so the poster matches the first live
+// frame as closely as a static image can.
+func renderPosterSVG(vis *VisualizationConfig, history map[string][]posterSample, theme Theme) string {
+	s := &posterSVG{
+		width:   float64(vis.CanvasWidth),
+		height:  float64(vis.CanvasHeight),
+		history: history,
+		theme: map[string]string{
+			"text": theme.Text, "background": theme.Background, "border": theme.Border,
+			"accent": theme.Accent, "subtle": theme.Subtle, "canvas": theme.Canvas, "grid": theme.Grid,
+		},
+	}
+	if w := vis.World; w != nil {
+		s.world = newPosterWorld(w, s.width, s.height)
+	}
+	fmt.Fprintf(&s.b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n",
+		vis.CanvasWidth, vis.CanvasHeight, vis.CanvasWidth, vis.CanvasHeight)
+	if bg := s.color(vis.BackgroundColor); bg != "" {
+		fmt.Fprintf(&s.b, `<rect width="%d" height="%d" fill="%s"/>`+"\n", vis.CanvasWidth, vis.CanvasHeight, html.EscapeString(bg))
+	}
+	for _, r := range vis.Renderers {
+		s.renderElement(r)
+	}
+	s.b.WriteString("</svg>\n")
+	return s.b.String()
+}
+
+// posterWorld is renderer.js's worldTransform.
+type posterWorld struct {
+	minX, minY, maxY, sx, sy, ox, oy float64
+	yUp                              bool
+}
+
+func newPosterWorld(w *WorldCoordinates, width, height float64) *posterWorld {
+	t := &posterWorld{minX: w.MinX, minY: w.MinY, maxY: w.MaxY, yUp: w.YUp,
+		sx: width / (w.MaxX - w.MinX), sy: height / (w.MaxY - w.MinY)}
+	if w.LockAspect {
+		s := math.Min(t.sx, t.sy)
+		t.ox = (width - s*(w.MaxX-w.MinX)) / 2
+		t.oy = (height - s*(w.MaxY-w.MinY)) / 2
+		t.sx, t.sy = s, s
+	}
+	return t
+}
+
+type posterSVG struct {
+	b             strings.Builder
+	width, height float64
+	world         *posterWorld
+	history       map[string][]posterSample
+	theme         map[string]string
+}
+
+// posterProps reads renderer properties with JavaScript's `p.x || def`
+// semantics: a missing, zero or empty value takes the default.
+type posterProps map[string]interface{}
+
+func (p posterProps) num(key string, def float64) float64 {
+	var v float64
+	switch n := p[key].(type) {
+	case float64:
+		v = n
+	case float32:
+		v = float64(n)
+	case int:
+		v = float64(n)
+	case int64:
+		v = float64(n)
+	}
+	if v == 0 || math.IsNaN(v) {
+		return def
+	}
+	return v
+}
+
+func (p posterProps) str(key, def string) string {
+	if v, ok := p[key].(string); ok && v != "" {
+		return v
+	}
+	return def
+}
+
+func (p posterProps) flag(key string) bool {
+	v, _ := p[key].(bool)
+	return v
+}
+
+func (p posterProps) sub(key string) posterProps {
+	v, _ := p[key].(map[string]interface{})
+	return v
+}
+
+// color resolves a theme token to the theme's colour.
+func (s *posterSVG) color(c string) string {
+	if name, ok := strings.CutPrefix(c, "theme:"); ok {
+		return s.theme[name]
+	}
+	return c
+}
+
+func (s *posterSVG) toCanvas(x, y float64) (float64, float64) {
+	t := s.world
+	if t == nil {
+		return x, y
+	}
+	if t.yUp {
+		return t.ox + (x-t.minX)*t.sx, t.oy + (t.maxY-y)*t.sy
+	}
+	return t.ox + (x-t.minX)*t.sx, t.oy + (y-t.minY)*t.sy
+}
+
+func (s *posterSVG) worldSize(w, h float64) (float64, float64) {
+	if s.world == nil {
+		return w, h
+	}
+	return w * s.world.sx, h * s.world.sy
+}
+
+// latest is a partition's last output state, or nil.
+func (s *posterSVG) latest(partition string) []float64 {
+	h := s.history[partition]
+	if len(h) == 0 {
+		return nil
+	}
+	return h[len(h)-1].values
+}
+
+// chartHistory is the window of samples a chart plots, as in renderer.js.
+func (s *posterSVG) chartHistory(partition string, window posterProps) []posterSample {
+	recent := s.history[partition]
+	switch {
+	case window.flag("full"):
+		maxPoints := int(window.num("maxPoints", 500))
+		stride := 1
+		for len(recent)/stride >= 2*maxPoints {
+			stride *= 2
+		}
+		var points []posterSample
+		for i := 0; i < len(recent); i += stride {
+			points = append(points, recent[i])
+		}
+		if len(recent) > 0 && (len(recent)-1)%stride != 0 {
+			points = append(points, recent[len(recent)-1])
+		}
+		return points
+	case window.num("time", 0) > 0:
+		if len(recent) == 0 {
+			return recent
+		}
+		cutoff := recent[len(recent)-1].time - window.num("time", 0)
+		var points []posterSample
+		for _, pt := range recent {
+			if pt.time >= cutoff {
+				points = append(points, pt)
+			}
+		}
+		return points
+	}
+	n := int(window.num("samples", 100))
+	if len(recent) > n {
+		return recent[len(recent)-n:]
+	}
+	return recent
+}
+
+// bind applies a renderer's property bindings to a copy of its
+// properties, as renderer.js's bind does.
+func (s *posterSVG) bind(r RendererConfig) posterProps {
+	p := s.resolve(r.Properties).(map[string]interface{})
+	for name, b := range toJSBindings(r) {
+		b := b.(map[string]interface{})
+		state := s.latest(b["partition"].(string))
+		index := b["index"].(int)
+		if index >= len(state) {
+			continue
+		}
+		v := state[index]*b["scale"].(float64) + b["offset"].(float64)
+		if tmpl, ok := b["template"].(string); ok {
+			p[name] = strings.Replace(tmpl, "{value}", strconv.FormatFloat(v, 'f', b["decimals"].(int), 64), 1)
+		} else {
+			p[name] = v
+		}
+	}
+	return p
+}
+
+// resolve returns a copy of a property value with every theme token
+// replaced by the theme's colour.
+func (s *posterSVG) resolve(v interface{}) interface{} {
+	switch v := v.(type) {
+	case string:
+		return s.color(v)
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			out[k] = s.resolve(e)
+		}
+		return out
+	case []map[string]interface{}:
+		out := make([]map[string]interface{}, len(v))
+		for i, e := range v {
+			out[i] = s.resolve(e).(map[string]interface{})
+		}
+		return out
+	case []string:
+		out := make([]string, len(v))
+		for i, e := range v {
+			out[i] = s.color(e)
+		}
+		return out
+	}
+	return v
+}
+
+func (s *posterSVG) renderElement(r RendererConfig) {
+	state := s.latest(r.PartitionName)
+	if state == nil && r.PartitionName != "" {
+		return
+	}
+	p := s.bind(r)
+	switch r.Type {
+	case "text":
+		s.renderText(p, state)
+	case "circle":
+		s.renderCircle(p)
+	case "rectangle":
+		s.renderRectangle(p)
+	case "rectangleSet":
+		s.renderRectangleSet(p, state)
+	case "line":
+		s.renderLine(p)
+	case "barChart":
+		s.renderBarChart(p, state)
+	case "lineChart":
+		s.renderLineChart(p, r.PartitionName)
+	case "multiLineChart":
+		s.renderMultiLineChart(p)
+	case "progressBar":
+		s.renderProgressBar(p, state)
+	case "scatter":
+		s.renderScatter(p, state)
+	case "pointSet", "playerSet":
+		s.renderPointSet(p, state)
+	}
+}
+
+// position is a shape's (x, y): world coordinates if the visualization
+// has a world, else pixels where 0 means the canvas centre.
+func (s *posterSVG) position(p posterProps) (float64, float64) {
+	if s.world != nil {
+		return s.toCanvas(p.num("x", 0), p.num("y", 0))
+	}
+	return p.num("x", s.width/2), p.num("y", s.height/2)
+}
+
+func (s *posterSVG) renderText(p posterProps, state []float64) {
+	v := 0.0
+	if len(state) > 0 {
+		v = state[0]
+	}
+	text := strings.Replace(p.str("text", "{value}"), "{value}", strconv.FormatFloat(math.Floor(v), 'f', -1, 64), 1)
+	x, y := s.position(p)
+	s.text(x, y, text, p.num("fontSize", 16), p.str("fontFamily", "Arial"), p.str("textAlign", "center"), p.str("color", s.theme["text"]))
+}
+
+func (s *posterSVG) renderCircle(p posterProps) {
+	x, y := s.position(p)
+	radius := p.num("radius", 10)
+	if s.world != nil {
+		radius = p.num("radius", 0) * math.Min(s.world.sx, s.world.sy)
+	}
+	fmt.Fprintf(&s.b, `<circle cx="%s" cy="%s" r="%s"%s/>`+"\n", svgNum(x), svgNum(y), svgNum(radius), s.shapePaint(p))
+}
+
+func (s *posterSVG) renderRectangle(p posterProps) {
+	x, y := s.toCanvas(p.num("x", 0), p.num("y", 0))
+	w, h := p.num("width", 50), p.num("height", 50)
+	if s.world != nil {
+		w, h = s.worldSize(p.num("width", 0), p.num("height", 0))
+	}
+	s.rect(x, y, w, h, s.shapePaint(p))
+}
+
+// shapePaint is a circle or rectangle's fill and stroke attributes: the
+// fill and stroke colours if set, else a fill of color.
+func (s *posterSVG) shapePaint(p posterProps) string {
+	fill, stroke := p.str("fillColor", ""), p.str("strokeColor", "")
+	if fill == "" && stroke == "" {
+		fill = p.str("color", s.theme["text"])
+	}
+	attrs := ` fill="none"`
+	if fill != "" {
+		attrs = ` fill="` + html.EscapeString(fill) + `"`
+	}
+	if stroke != "" {
+		attrs += ` stroke="` + html.EscapeString(stroke) + `" stroke-width="` + svgNum(p.num("strokeWidth", 1)) + `"`
+	}
+	return attrs
+}
+
+func (s *posterSVG) renderRectangleSet(p posterProps, state []float64) {
+	fill := p.str("fillColor", p.str("color", s.theme["text"]))
+	paint := ` fill="` + html.EscapeString(fill) + `"`
+	if stroke := p.str("strokeColor", ""); stroke != "" {
+		paint += ` stroke="` + html.EscapeString(stroke) + `" stroke-width="` + svgNum(p.num("strokeWidth", 1)) + `"`
+	}
+	topLeft := p.str("anchor", "") == "topLeft"
+	for i := 0; i+3 < len(state); i += 4 {
+		x, y, w, h := state[i], state[i+1], state[i+2], state[i+3]
+		if math.IsNaN(w) || math.IsInf(w, 0) {
+			w = p.num("defaultWidth", 12)
+		}
+		if math.IsNaN(h) || math.IsInf(h, 0) {
+			h = p.num("defaultHeight", 8)
+		}
+		if w <= 0 || h <= 0 || math.IsNaN(x+y) || math.IsInf(x+y, 0) {
+			continue
+		}
+		dw, dh := s.worldSize(w, h)
+		cx, cy := s.toCanvas(x, y)
+		if !topLeft {
+			cx, cy = cx-dw/2, cy-dh/2
+		}
+		s.rect(cx, cy, dw, dh, paint)
+	}
+}
+
+func (s *posterSVG) renderLine(p posterProps) {
+	x1, y1 := s.toCanvas(p.num("x1", 0), p.num("y1", 0))
+	x2, y2 := p.num("x2", 50), p.num("y2", 50)
+	if s.world != nil {
+		x2, y2 = s.toCanvas(p.num("x2", 0), p.num("y2", 0))
+	}
+	fmt.Fprintf(&s.b, `<line x1="%s" y1="%s" x2="%s" y2="%s" stroke="%s" stroke-width="%s"/>`+"\n",
+		svgNum(x1), svgNum(y1), svgNum(x2), svgNum(y2), html.EscapeString(p.str("color", s.theme["text"])), svgNum(p.num("width", 1)))
+}
+
+// chartArea is renderer.js's chartArea: the plot rectangle inside a
+// chart's box, leaving room for its axis tick labels and titles.
+func chartArea(p posterProps, x, y, width, height float64) (ax, ay, aw, ah float64) {
+	left, bottom := 0.0, 0.0
+	if a := p.sub("yAxis"); a != nil {
+		if a.num("tickCount", 0) > 1 {
+			left += 40
+		}
+		if a.str("title", "") != "" {
+			left += 16
+		}
+	}
+	if a := p.sub("xAxis"); a != nil {
+		if a.num("tickCount", 0) > 1 {
+			bottom += 16
+		}
+		if a.str("title", "") != "" {
+			bottom += 16
+		}
+	}
+	return x + left, y, math.Max(width-left, 1), math.Max(height-bottom, 1)
+}
+
+func (s *posterSVG) renderBarChart(p posterProps, state []float64) {
+	x, y, w, h := chartArea(p, p.num("x", 0), p.num("y", 0), p.num("width", 50), p.num("height", 50))
+	maxValue := p.num("maxValue", 100)
+	value := 0.0
+	if len(state) > 0 {
+		value = state[0]
+	}
+	frac := math.Min(value/maxValue, 1)
+	s.rect(x, y, w, h, ` fill="`+html.EscapeString(s.theme["grid"])+`"`)
+	s.rect(x, y+h*(1-frac), w, h*frac, ` fill="`+html.EscapeString(p.str("color", s.theme["accent"]))+`"`)
+	if p.flag("showLabels") {
+		s.text(x+w/2, y+h/2, strconv.FormatFloat(math.Floor(value), 'f', -1, 64), 12, "Arial", "center", s.theme["text"])
+	}
+}
+
+func (s *posterSVG) renderLineChart(p posterProps, partition string) {
+	history := s.chartHistory(partition, p.sub("history"))
+	if len(history) < 2 {
+		return
+	}
+	x, y, w, h := chartArea(p, p.num("x", 0), p.num("y", 0), p.num("width", 50), p.num("height", 50))
+	minVal, maxVal := math.Inf(1), math.Inf(-1)
+	for _, pt := range history {
+		v := firstValue(pt)
+		minVal, maxVal = math.Min(minVal, v), math.Max(maxVal, v)
+	}
+	valueRange := math.Max(maxVal-minVal, 0.1)
+	byTime := p.str("xAxisMode", "") == "time"
+	xOf := func(i int) float64 {
+		if byTime {
+			return history[i].time
+		}
+		return float64(i)
+	}
+	xMin, xMax := xOf(0), xOf(len(history)-1)
+	xRange := math.Max(xMax-xMin, 1e-9)
+	points := make([]string, len(history))
+	for i, pt := range history {
+		points[i] = svgNum(x+(xOf(i)-xMin)/xRange*w) + "," + svgNum(y+h-(firstValue(pt)-minVal)/valueRange*h)
+	}
+	s.polyline(points, p.str("color", s.theme["accent"]), p.num("lineWidth", 2))
+}
+
+func firstValue(pt posterSample) float64 {
+	if len(pt.values) == 0 {
+		return 0
+	}
+	return pt.values[0]
+}
+
+// posterPalette is renderer.js's SERIES_PALETTE.
+var posterPalette = []string{"#3c78d8", "#e06666", "#6aa84f", "#f1c232", "#8e7cc3", "#e69138"}
+
+func (s *posterSVG) renderMultiLineChart(p posterProps) {
+	x, y, w, h := chartArea(p, p.num("x", 0), p.num("y", 0), p.num("width", 200), p.num("height", 100))
+	textColor := p.str("textColor", s.theme["text"])
+	series, _ := p["series"].([]map[string]interface{})
+
+	type point struct{ t, v float64 }
+	lines := make([][]point, len(series))
+	tMin, tMax, vMin, vMax := math.Inf(1), math.Inf(-1), math.Inf(1), math.Inf(-1)
+	for i, sp := range series {
+		sp := posterProps(sp)
+		index := int(sp.num("index", 0))
+		for _, pt := range s.chartHistory(sp.str("partition", ""), p.sub("history")) {
+			if index >= len(pt.values) || math.IsNaN(pt.values[index]) || math.IsInf(pt.values[index], 0) {
+				continue
+			}
+			v := pt.values[index]
+			lines[i] = append(lines[i], point{pt.time, v})
+			tMin, tMax = math.Min(tMin, pt.time), math.Max(tMax, pt.time)
+			vMin, vMax = math.Min(vMin, v), math.Max(vMax, v)
+		}
+	}
+	if p.flag("fixedYRange") {
+		vMin, vMax = p.num("minY", 0), p.num("maxY", 0)
+	}
+	if p.sub("xAxis") == nil && p.sub("yAxis") == nil {
+		s.polyline([]string{svgNum(x) + "," + svgNum(y), svgNum(x) + "," + svgNum(y+h), svgNum(x+w) + "," + svgNum(y+h)}, textColor, 1)
+	}
+	if !math.IsInf(tMin, 0) && !math.IsInf(vMin, 0) {
+		vRange, tRange := math.Max(vMax-vMin, 0.1), math.Max(tMax-tMin, 1e-9)
+		for i, line := range lines {
+			if len(line) < 2 {
+				continue
+			}
+			points := make([]string, len(line))
+			for j, pt := range line {
+				frac := math.Max(0, math.Min(1, (pt.v-vMin)/vRange))
+				points[j] = svgNum(x+(pt.t-tMin)/tRange*w) + "," + svgNum(y+h-frac*h)
+			}
+			s.polyline(points, posterProps(series[i]).str("color", posterPalette[i%len(posterPalette)]), p.num("lineWidth", 2))
+		}
+	}
+	if p.flag("hideLegend") {
+		return
+	}
+	row := 0.0
+	for i, sp := range series {
+		sp := posterProps(sp)
+		if sp.str("label", "") == "" {
+			continue
+		}
+		ly := y + 8 + row*16
+		s.rect(x+8, ly-5, 10, 10, ` fill="`+html.EscapeString(sp.str("color", posterPalette[i%len(posterPalette)]))+`"`)
+		s.text(x+24, ly+4, sp.str("label", ""), 12, "Arial", "left", textColor)
+		row++
+	}
+}
+
+func (s *posterSVG) renderProgressBar(p posterProps, state []float64) {
+	x, y := p.num("x", 0), p.num("y", 0)
+	w, h := p.num("width", 100), p.num("height", 20)
+	maxValue := p.num("maxValue", 100)
+	value := 0.0
+	if len(state) > 0 {
+		value = math.Max(0, math.Min(state[0], maxValue))
+	}
+	s.rect(x, y, w, h, ` fill="`+html.EscapeString(p.str("backgroundColor", s.theme["grid"]))+`"`)
+	s.rect(x, y, w*value/maxValue, h, ` fill="`+html.EscapeString(p.str("foregroundColor", s.theme["accent"]))+`"`)
+	if border := p.str("borderColor", ""); border != "" {
+		s.rect(x, y, w, h, ` fill="none" stroke="`+html.EscapeString(border)+`" stroke-width="`+svgNum(p.num("borderWidth", 1))+`"`)
+	}
+	if p.flag("showLabel") {
+		s.text(x+w/2, y+h/2+4, strconv.FormatFloat(math.Floor(value), 'f', -1, 64)+"%", 12, "Arial", "center", s.theme["text"])
+	}
+}
+
+// renderScatter draws every point in the scatter's colour; colour and
+// size channels aren't mapped.
+func (s *posterSVG) renderScatter(p posterProps, state []float64) {
+	x, y, w, h := chartArea(p, p.num("x", 0), p.num("y", 0), p.num("width", 200), p.num("height", 200))
+	stride := 2
+	if p.flag("colorChannel") {
+		stride++
+	}
+	if p.flag("sizeChannel") {
+		stride++
+	}
+	xr := channelRange(state, 0, stride)
+	yr := channelRange(state, 1, stride)
+	if p.flag("fixedRange") {
+		xr = [2]float64{p.num("minX", 0), p.num("maxX", 0)}
+		yr = [2]float64{p.num("minY", 0), p.num("maxY", 0)}
+	}
+	fill := html.EscapeString(p.str("color", s.theme["accent"]))
+	radius := p.num("radius", 3)
+	for i := 0; i+1 < len(state); i += stride {
+		fx := (state[i] - xr[0]) / (xr[1] - xr[0])
+		fy := (state[i+1] - yr[0]) / (yr[1] - yr[0])
+		if !(fx >= 0 && fx <= 1 && fy >= 0 && fy <= 1) {
+			continue
+		}
+		fmt.Fprintf(&s.b, `<circle cx="%s" cy="%s" r="%s" fill="%s"/>`+"\n",
+			svgNum(x+fx*w), svgNum(y+h-fy*h), svgNum(radius), fill)
+	}
+}
+
+// channelRange is renderer.js's channelRange without fixed bounds.
+func channelRange(values []float64, start, stride int) [2]float64 {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for i := start; i < len(values); i += stride {
+		lo, hi = math.Min(lo, values[i]), math.Max(hi, values[i])
+	}
+	if hi > lo {
+		return [2]float64{lo, hi}
+	}
+	if !math.IsInf(lo, 0) {
+		return [2]float64{lo - 0.5, lo + 0.5}
+	}
+	return [2]float64{0, 1}
+}
+
+func (s *posterSVG) renderPointSet(p posterProps, state []float64) {
+	fill := p.str("fillColor", p.str("color", s.theme["text"]))
+	paint := ` fill="` + html.EscapeString(fill) + `"`
+	if stroke := p.str("strokeColor", ""); stroke != "" {
+		paint += ` stroke="` + html.EscapeString(stroke) + `" stroke-width="` + svgNum(p.num("strokeWidth", 1)) + `"`
+	}
+	radius := p.num("radius", 8)
+	for i := 0; i+1 < len(state); i += 2 {
+		x, y := s.toCanvas(state[i], state[i+1])
+		fmt.Fprintf(&s.b, `<circle cx="%s" cy="%s" r="%s"%s/>`+"\n", svgNum(x), svgNum(y), svgNum(radius), paint)
+	}
+}
+
+func (s *posterSVG) rect(x, y, w, h float64, paint string) {
+	fmt.Fprintf(&s.b, `<rect x="%s" y="%s" width="%s" height="%s"%s/>`+"\n", svgNum(x), svgNum(y), svgNum(w), svgNum(h), paint)
+}
+
+func (s *posterSVG) polyline(points []string, color string, width float64) {
+	fmt.Fprintf(&s.b, `<polyline points="%s" fill="none" stroke="%s" stroke-width="%s" stroke-linejoin="round"/>`+"\n",
+		strings.Join(points, " "), html.EscapeString(color), svgNum(width))
+}
+
+// text draws a canvas fillText: align is a canvas textAlign.
+func (s *posterSVG) text(x, y float64, text string, size float64, family, align, color string) {
+	anchor := "middle"
+	switch align {
+	case "left", "start":
+		anchor = "start"
+	case "right", "end":
+		anchor = "end"
+	}
+	fmt.Fprintf(&s.b, `<text x="%s" y="%s" font-size="%s" font-family="%s" text-anchor="%s" fill="%s">%s</text>`+"\n",
+		svgNum(x), svgNum(y), svgNum(size), html.EscapeString(family), anchor, html.EscapeString(color), html.EscapeString(text))
+}
+
+// svgNum formats a coordinate to two decimal places, trimming zeros.
+func svgNum(v float64) string {
+	s := strconv.FormatFloat(v, 'f', 2, 64)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "-0" {
+		return "0"
+	}
+	return s
+}
@@ -0,0 +1,129 @@
+package dashboard_test
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/umbralcalc/dexetera/pkg/dashboard"
+	"github.com/umbralcalc/dexetera/pkg/growth"
+	"github.com/umbralcalc/stochadex/pkg/simulator"
+)
+
+// posterSVGs generates cfg's widget with a poster and returns the
+// decoded SVG of each canvas.
+func posterSVGs(t *testing.T, cfg *dashboard.Config, steps int) []string {
+	t.Helper()
+	dir := t.TempDir()
+	err := dashboard.GenerateWidget(cfg, dashboard.WidgetOptions{
+		OutputDir: dir,
+		Poster:    &dashboard.PosterOptions{Steps: steps},
+	})
+	if err != nil {
+		t.Fatalf("GenerateWidget: %v", err)
+	}
+	raw, err := os.ReadFile(filepath.Join(dir, "widget.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var svgs []string
+	for _, m := range regexp.MustCompile(`<img data-poster="[^"]*" src="data:image/svg\+xml;base64,([^"]+)"`).FindAllStringSubmatch(string(raw), -1) {
+		svg, err := base64.StdEncoding.DecodeString(m[1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		svgs = append(svgs, string(svg))
+	}
+	return svgs
+}
+
+func TestGenerateWidget_Poster(t *testing.T) {
+	t.Run("line chart of the simulated run", func(t *testing.T) {
+		svgs := posterSVGs(t, growth.NewConfig(), 50)
+		if len(svgs) != 1 {
+			t.Fatalf("expected one poster, got %d", len(svgs))
+		}
+		m := regexp.MustCompile(`<polyline points="([^"]+)"`).FindStringSubmatch(svgs[0])
+		if m == nil {
+			t.Fatalf("poster has no line chart:\n%s", svgs[0])
+		}
+		if n := len(strings.Fields(m[1])); n != 51 {
+			t.Errorf("line chart has %d points, want the initial state and one per step (51)", n)
+		}
+	})
+
+	t.Run("slider defaults and theme colours", func(t *testing.T) {
+		// With r at 0 the population stays at its initial 10.
+		cfg := dashboard.NewConfigBuilder("poster").
+			WithServerPartition("population").
+			WithActionStatePartition("population").
+			WithVisualization(dashboard.NewVisualizationBuilder().
+				WithCanvas(200, 100).
+				AddText("population", "N = {value}", 100, 50, &dashboard.TextOptions{Color: dashboard.ThemeAccent}).
+				AddCircle("", 20, 20, 5, nil).
+				AddBarChart("population", 150, 0, 40, 100, &dashboard.ChartOptions{Color: "#e06666", MaxValue: 20}).
+				Build()).
+			WithSimulation(growth.BuildGrowthSimulation).
+			WithSlider(dashboard.Slider{Name: "r", Partition: "population", ValueIndex: 0, Max: 1}).
+			WithSlider(dashboard.Slider{Name: "K", Partition: "population", ValueIndex: 1, Max: 1000, Default: 500}).
+			Build()
+		svgs := posterSVGs(t, cfg, 20)
+		if len(svgs) != 1 {
+			t.Fatalf("expected one poster, got %d", len(svgs))
+		}
+		for _, want := range []string{
+			`<rect width="200" height="100" fill="#ffffff"/>`,
+			`fill="#3c78d8">N = 10</text>`,
+			// Uncoloured shapes default to the theme's text colour.
+			`<circle cx="20" cy="20" r="5" fill="#2c3e50"/>`,
+			// A coloured bar keeps the theme's track behind it.
+			`fill="rgba(44,62,80,0.15)"/>`,
+			`fill="#e06666"/>`,
+		} {
+			if !strings.Contains(svgs[0], want) {
+				t.Errorf("poster is missing %q:\n%s", want, svgs[0])
+			}
+		}
+	})
+
+	t.Run("widget.html and test.html share one run", func(t *testing.T) {
+		cfg := growth.NewConfig()
+		runs := 0
+		cfg.SimulationGenerator = func() *simulator.ConfigGenerator {
+			runs++
+			return growth.BuildGrowthSimulation()
+		}
+		posterSVGs(t, cfg, 10)
+		if runs != 1 {
+			t.Errorf("simulation ran %d times, want 1", runs)
+		}
+	})
+
+	t.Run("a panicking simulation is an error", func(t *testing.T) {
+		cfg := baseBuilder().
+			WithSimulation(func() *simulator.ConfigGenerator { panic("boom") }).
+			Build()
+		err := dashboard.GenerateWidget(cfg, dashboard.WidgetOptions{
+			OutputDir: t.TempDir(),
+			Poster:    &dashboard.PosterOptions{},
+		})
+		expectError(t, err, `poster simulation for "test" failed: panic: boom`)
+	})
+
+	t.Run("no poster by default", func(t *testing.T) {
+		dir := t.TempDir()
+		if err := dashboard.GenerateWidget(growth.NewConfig(), dashboard.WidgetOptions{OutputDir: dir}); err != nil {
+			t.Fatal(err)
+		}
+		raw, err := os.ReadFile(filepath.Join(dir, "widget.html"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(raw), "<img data-poster") {
+			t.Errorf("widget.html has a poster without PosterOptions")
+		}
+	})
+}
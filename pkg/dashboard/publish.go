@@ -0,0 +1,188 @@
+package dashboard
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// PublishOptions says where PublishAssets copies a widget's files and the
+// URL they are served from.
+type PublishOptions struct {
+	// StaticDir is the site's static directory to copy into, e.g.
+	// "../blog/assets/dexetera". Required.
+	StaticDir string
+
+	// BaseURL is the URL StaticDir is served at, e.g. "/assets/dexetera/".
+	// Defaults to "/".
+	BaseURL string
+
+	// Widget configures the widget.html written alongside. PublishAssets
+	// publishes the runtime from its RuntimeDir and the wasm from its
+	// BundleWasmPath, sets its runtime, wasm and image URLs, and ignores
+	// Bundle. StrictCSP is rejected: its widget.css and widget.js would
+	// be pinned by integrity hash at unhashed URLs, so a cached stale
+	// copy would stop the widget starting. WebComponent is rejected too,
+	// as its element script and spec would be served under fixed names.
+	Widget WidgetOptions
+}
+
+// AssetManifest maps each published file's source name
+// ("runtime/renderer.js", "main.wasm", "assets/sprites/ship.png") to the
+// content-hashed URL it was published at.
+type AssetManifest map[string]string
+
+// publishedURLs are the content-hashed URLs a published widget loads.
+type publishedURLs struct {
+	Renderer string
+	Worker   string
+	Assets   map[string]string
+}
+
+// PublishAssets copies the runtime, the built wasm and the Config's local
+// images into opts.StaticDir under content-hashed filenames, so that
+// caches never serve a stale copy after an update, then generates the
+// widget as GenerateWidget does with widget.html loading the hashed URLs.
+//
+// Files land under StaticDir as:
+//
+//	runtime/<file>.<hash>.js                 Shared by every widget.
+//	widgets/<name>/main.<hash>.wasm
+//	widgets/<name>/assets/<path>.<hash>.<ext>
+//	widgets/<name>/manifest.json             The returned AssetManifest.
+//
+// Old hashed files are left in place for pages that still refer to them.
+func PublishAssets(config *Config, opts PublishOptions) (AssetManifest, error) {
+	if opts.StaticDir == "" {
+		return nil, fmt.Errorf("publish: no StaticDir")
+	}
+	if opts.Widget.StrictCSP {
+		return nil, fmt.Errorf("publish: StrictCSP widgets can't be published with hashed names")
+	}
+	if opts.Widget.WebComponent {
+		return nil, fmt.Errorf("publish: WebComponent widgets can't be published with hashed names")
+	}
+	w := opts.Widget
+	w.applyDefaults(config.Name)
+	if opts.BaseURL == "" {
+		opts.BaseURL = "/"
+	}
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	wasm, err := os.ReadFile(w.BundleWasmPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read wasm (run build.sh first): %w", err)
+	}
+
+	manifest := AssetManifest{}
+	publish := func(source, dest string, data []byte) (string, error) {
+		dest = hashedName(dest, data)
+		full := filepath.Join(opts.StaticDir, filepath.FromSlash(dest))
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			return "", err
+		}
+		if err := os.WriteFile(full, data, 0644); err != nil {
+			return "", fmt.Errorf("failed to write %s: %w", dest, err)
+		}
+		url := strings.TrimSuffix(opts.BaseURL, "/") + "/" + dest
+		manifest[source] = url
+		return dest, nil
+	}
+
+	// Every runtime script but worker.js, which needs their hashed names.
+	imports := map[string]string{}
+	var worker []byte
+	err = filepath.WalkDir(w.RuntimeDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || filepath.Ext(p) != ".js" {
+			return err
+		}
+		rel, err := filepath.Rel(w.RuntimeDir, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		if rel == "worker.js" {
+			worker = data
+			return nil
+		}
+		dest, err := publish("runtime/"+rel, "runtime/"+rel, data)
+		if err != nil {
+			return err
+		}
+		imports[rel] = strings.TrimPrefix(dest, "runtime/")
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to publish runtime: %w", err)
+	}
+	if worker == nil {
+		return nil, fmt.Errorf("failed to publish runtime: no worker.js in %s", w.RuntimeDir)
+	}
+	// The worker's import map is part of its content, so a change to any
+	// runtime file also changes the worker's hash.
+	importsJSON, err := json.Marshal(imports)
+	if err != nil {
+		return nil, err
+	}
+	worker = append([]byte("self.dexeteraRuntimeFiles = "+string(importsJSON)+";\n"), worker...)
+	if _, err := publish("runtime/worker.js", "runtime/worker.js", worker); err != nil {
+		return nil, err
+	}
+
+	widgetDir := "widgets/" + config.Name + "/"
+	if _, err := publish("main.wasm", widgetDir+"main.wasm", wasm); err != nil {
+		return nil, err
+	}
+
+	assets := map[string]string{}
+	for _, p := range config.localAssets() {
+		data, err := os.ReadFile(filepath.Join(w.AssetSourceDir, filepath.FromSlash(p)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read image %q: %w", p, err)
+		}
+		if _, err := publish("assets/"+p, widgetDir+"assets/"+p, data); err != nil {
+			return nil, err
+		}
+		assets[p] = manifest["assets/"+p]
+	}
+
+	raw, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := writeFile(filepath.Join(opts.StaticDir, filepath.FromSlash(widgetDir)), "manifest.json", string(raw)+"\n"); err != nil {
+		return nil, err
+	}
+
+	w.Bundle = false
+	w.WasmURL = manifest["main.wasm"]
+	w.published = &publishedURLs{
+		Renderer: manifest["runtime/renderer.js"],
+		Worker:   manifest["runtime/worker.js"],
+		Assets:   assets,
+	}
+	if err := GenerateWidget(config, w); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// hashedName inserts a short content hash before name's extension:
+// "runtime/renderer.js" becomes "runtime/renderer.3f9a0c1b2d.js".
+func hashedName(name string, data []byte) string {
+	sum := sha256.Sum256(data)
+	ext := path.Ext(name)
+	return strings.TrimSuffix(name, ext) + "." + hex.EncodeToString(sum[:])[:10] + ext
+}
@@ -0,0 +1,102 @@
+package dashboard_test
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/umbralcalc/dexetera/pkg/dashboard"
+)
+
+func TestPublishAssets(t *testing.T) {
+	wasm := filepath.Join(t.TempDir(), "main.wasm")
+	if err := os.WriteFile(wasm, []byte("\x00asm fake"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	static := t.TempDir()
+	out := t.TempDir()
+	publish := func() dashboard.AssetManifest {
+		t.Helper()
+		manifest, err := dashboard.PublishAssets(baseBuilder().Build(), dashboard.PublishOptions{
+			StaticDir: static,
+			BaseURL:   "/assets/dexetera/",
+			Widget:    dashboard.WidgetOptions{OutputDir: out, RuntimeDir: "../../runtime", BundleWasmPath: wasm},
+		})
+		if err != nil {
+			t.Fatalf("PublishAssets: %v", err)
+		}
+		return manifest
+	}
+	manifest := publish()
+
+	hashed := regexp.MustCompile(`^/assets/dexetera/runtime/renderer\.[0-9a-f]{10}\.js$`)
+	if !hashed.MatchString(manifest["runtime/renderer.js"]) {
+		t.Fatalf("unexpected renderer URL %q", manifest["runtime/renderer.js"])
+	}
+	for _, name := range []string{"runtime/worker.js", "runtime/drivers/inline.js", "main.wasm"} {
+		url, ok := manifest[name]
+		if !ok {
+			t.Fatalf("manifest has no %s", name)
+		}
+		rel := strings.TrimPrefix(url, "/assets/dexetera/")
+		if _, err := os.Stat(filepath.Join(static, filepath.FromSlash(rel))); err != nil {
+			t.Errorf("%s was not published at %s: %v", name, rel, err)
+		}
+	}
+
+	worker, err := os.ReadFile(filepath.Join(static, strings.TrimPrefix(manifest["runtime/worker.js"], "/assets/dexetera/")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(worker), `self.dexeteraRuntimeFiles = {`) ||
+		!strings.Contains(string(worker), `"drivers/inline.js":"drivers/inline.`) {
+		t.Errorf("published worker does not map its imports to hashed names")
+	}
+
+	widget, err := os.ReadFile(filepath.Join(out, "widget.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"var RENDERER_URL = '" + manifest["runtime/renderer.js"] + "';",
+		"var WORKER_URL = '" + manifest["runtime/worker.js"] + "';",
+		"var WASM_URL = '" + manifest["main.wasm"] + "';",
+	} {
+		if !strings.Contains(string(widget), want) {
+			t.Errorf("widget.html is missing %q", want)
+		}
+	}
+	test, err := os.ReadFile(filepath.Join(out, "test.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(test), "var RENDERER_URL = '../runtime/renderer.js';") {
+		t.Errorf("test.html should keep loading the local runtime")
+	}
+
+	if again := publish(); again["runtime/worker.js"] != manifest["runtime/worker.js"] {
+		t.Errorf("republishing unchanged files changed the worker URL")
+	}
+
+	t.Run("StrictCSP is rejected", func(t *testing.T) {
+		static := t.TempDir()
+		_, err := dashboard.PublishAssets(baseBuilder().Build(), dashboard.PublishOptions{
+			StaticDir: static,
+			Widget:    dashboard.WidgetOptions{OutputDir: t.TempDir(), StrictCSP: true, BundleWasmPath: wasm},
+		})
+		expectError(t, err, "StrictCSP widgets can't be published")
+		if entries, _ := os.ReadDir(static); len(entries) != 0 {
+			t.Errorf("a rejected publish still wrote %d entries to StaticDir", len(entries))
+		}
+	})
+
+	t.Run("WebComponent is rejected", func(t *testing.T) {
+		_, err := dashboard.PublishAssets(baseBuilder().Build(), dashboard.PublishOptions{
+			StaticDir: t.TempDir(),
+			Widget:    dashboard.WidgetOptions{OutputDir: t.TempDir(), WebComponent: true, BundleWasmPath: wasm},
+		})
+		expectError(t, err, "WebComponent widgets can't be published")
+	})
+}
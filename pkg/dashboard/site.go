@@ -0,0 +1,151 @@
+package dashboard
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SiteOptions configures GenerateSiteIntegrations.
+type SiteOptions struct {
+	// OutputDir is where the hugo/, jekyll/ and mkdocs/ trees are written.
+	// Defaults to "site/".
+	OutputDir string
+
+	// RuntimeBaseURL is the URL the site serves the runtime/ folder at.
+	// Defaults to "/dexetera/runtime/".
+	RuntimeBaseURL string
+
+	// WidgetsBaseURL is the URL under which each Config's files are
+	// served: its wasm at <WidgetsBaseURL><name>/main.wasm and its images
+	// under <WidgetsBaseURL><name>/assets/. Defaults to "/dexetera/widgets/".
+	WidgetsBaseURL string
+
+	// Widget configures every widget's body. Its OutputDir, WidgetID and
+	// URLs are set per Config. Bundle is honoured, reading each wasm from
+	// <name>/src/main.wasm; StrictCSP and WebComponent are not supported.
+	Widget WidgetOptions
+}
+
+func (o *SiteOptions) applyDefaults() {
+	if o.OutputDir == "" {
+		o.OutputDir = "site/"
+	}
+	if o.RuntimeBaseURL == "" {
+		o.RuntimeBaseURL = "/dexetera/runtime/"
+	}
+	if o.WidgetsBaseURL == "" {
+		o.WidgetsBaseURL = "/dexetera/widgets/"
+	}
+}
+
+// GenerateSiteIntegrations writes each Config's widget in the shapes
+// static-site generators include, so that a post embeds a widget by name
+// rather than by pasting widget.html:
+//
+//	hugo/layouts/shortcodes/dexetera.html  {{< dexetera "growth" >}}
+//	hugo/layouts/partials/dexetera/<name>.html
+//	jekyll/_includes/dexetera.html         {% include dexetera.html name="growth" %}
+//	jekyll/_includes/dexetera/<name>.html
+//	mkdocs/dexetera/<name>.md              --8<-- "dexetera/growth.md"
+//
+// Copy each tree over the site's own (the MkDocs files go in a
+// pymdownx.snippets base path). The site must also serve the runtime and
+// each Config's wasm and images at opts' URLs; PublishAssets is not used,
+// as the includes need stable, unhashed names.
+//
+// Each widget is baked with the element ID "dexetera-<name>", so a page
+// may embed a given Config only once: a second copy would share the ID
+// and bind to the first. Pages that repeat a widget should use
+// pkg/markdown, whose directives take an id.
+func GenerateSiteIntegrations(configs []*Config, opts SiteOptions) error {
+	opts.applyDefaults()
+	if opts.Widget.StrictCSP || opts.Widget.WebComponent {
+		return fmt.Errorf("site integrations support neither StrictCSP nor WebComponent")
+	}
+
+	seen := make(map[string]struct{}, len(configs))
+	for _, cfg := range configs {
+		if _, dup := seen[cfg.Name]; dup {
+			return fmt.Errorf("duplicate config name %q", cfg.Name)
+		}
+		seen[cfg.Name] = struct{}{}
+		if err := cfg.Validate(); err != nil {
+			return fmt.Errorf("invalid config %q: %w", cfg.Name, err)
+		}
+
+		w := opts.Widget
+		w.OutputDir = ""
+		// The default ID; see the one-embed-per-page note above.
+		w.WidgetID = ""
+		w.BundleWasmPath = ""
+		w.RuntimeBaseURL = opts.RuntimeBaseURL
+		w.WasmURL = opts.WidgetsBaseURL + cfg.Name + "/main.wasm"
+		w.AssetBaseURL = opts.WidgetsBaseURL + cfg.Name + "/assets/"
+		w.published = nil
+		w.posterRun = nil
+		w.applyDefaults(cfg.Name)
+		body, _, err := renderWidgetBody(cfg, w, "widget.js")
+		if err != nil {
+			return fmt.Errorf("failed to render widget %q: %w", cfg.Name, err)
+		}
+
+		for _, f := range []struct{ dir, name, contents string }{
+			{"hugo/layouts/partials/dexetera", cfg.Name + ".html", hugoEscape(body)},
+			{"jekyll/_includes/dexetera", cfg.Name + ".html", "{% raw %}" + body + "{% endraw %}\n"},
+			{"mkdocs/dexetera", cfg.Name + ".md", MarkdownHTMLBlock(body)},
+		} {
+			if err := writeSiteFile(opts.OutputDir, f.dir, f.name, f.contents); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := writeSiteFile(opts.OutputDir, "hugo/layouts/shortcodes", "dexetera.html", hugoShortcode); err != nil {
+		return err
+	}
+	return writeSiteFile(opts.OutputDir, "jekyll/_includes", "dexetera.html", jekyllInclude)
+}
+
+// hugoShortcode renders the partial named by its one argument.
+const hugoShortcode = `{{- $name := .Get 0 -}}
+{{- $partial := printf "dexetera/%s.html" $name -}}
+{{- if templates.Exists (printf "partials/%s" $partial) -}}
+{{- partial $partial . -}}
+{{- else -}}
+{{- errorf "dexetera: no widget named %q (%s)" $name .Position -}}
+{{- end -}}
+`
+
+// jekyllInclude includes the widget named by its name parameter.
+const jekyllInclude = `{% include dexetera/{{ include.name }}.html %}
+`
+
+// hugoEscape stops Hugo reading "{{" in a widget body, e.g. in a label,
+// as the start of a template action.
+func hugoEscape(body string) string {
+	return strings.ReplaceAll(body, "{{", `{{ "{{" }}`)
+}
+
+// MarkdownHTMLBlock drops a widget body's blank lines, which would end a
+// Markdown raw HTML block early and leave the rest to be read as
+// paragraphs and indented code.
+func MarkdownHTMLBlock(body string) string {
+	lines := strings.Split(body, "\n")
+	kept := lines[:0]
+	for _, l := range lines {
+		if strings.TrimSpace(l) != "" {
+			kept = append(kept, l)
+		}
+	}
+	return strings.Join(kept, "\n") + "\n"
+}
+
+func writeSiteFile(root, dir, name, contents string) error {
+	full := filepath.Join(root, filepath.FromSlash(dir))
+	if err := os.MkdirAll(full, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	return writeFile(full, name, contents)
+}
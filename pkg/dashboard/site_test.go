@@ -0,0 +1,63 @@
+package dashboard_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/umbralcalc/dexetera/pkg/dashboard"
+)
+
+func TestGenerateSiteIntegrations(t *testing.T) {
+	dir := t.TempDir()
+	cfg := baseBuilder().WithDescription("Reads {{ .Title }} literally.").Build()
+	err := dashboard.GenerateSiteIntegrations([]*dashboard.Config{cfg}, dashboard.SiteOptions{
+		OutputDir:      dir,
+		RuntimeBaseURL: "/static/runtime/",
+		WidgetsBaseURL: "/static/widgets/",
+	})
+	if err != nil {
+		t.Fatalf("GenerateSiteIntegrations: %v", err)
+	}
+	read := func(name string) string {
+		t.Helper()
+		raw, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(raw)
+	}
+
+	hugo := read("hugo/layouts/partials/dexetera/test.html")
+	for _, want := range []string{
+		"var RENDERER_URL = '/static/runtime/renderer.js';",
+		"var WASM_URL = '/static/widgets/test/main.wasm';",
+		"var ASSET_BASE = '/static/widgets/test/assets/';",
+		`Reads {{ "{{" }} .Title }} literally.`,
+	} {
+		if !strings.Contains(hugo, want) {
+			t.Errorf("hugo partial is missing %q", want)
+		}
+	}
+	if !strings.Contains(read("hugo/layouts/shortcodes/dexetera.html"), `printf "dexetera/%s.html" $name`) {
+		t.Errorf("hugo shortcode does not select the partial by name")
+	}
+
+	jekyll := read("jekyll/_includes/dexetera/test.html")
+	if !strings.HasPrefix(jekyll, "{% raw %}") || !strings.Contains(jekyll, "Reads {{ .Title }} literally.") {
+		t.Errorf("jekyll include should hold the raw widget body, got:\n%.200s", jekyll)
+	}
+	if !strings.Contains(read("jekyll/_includes/dexetera.html"), "{% include dexetera/{{ include.name }}.html %}") {
+		t.Errorf("jekyll include does not select the widget by name")
+	}
+
+	if strings.Contains(read("mkdocs/dexetera/test.md"), "\n\n") {
+		t.Errorf("mkdocs snippet has a blank line, which would end its HTML block")
+	}
+
+	t.Run("duplicate names are rejected", func(t *testing.T) {
+		err := dashboard.GenerateSiteIntegrations([]*dashboard.Config{cfg, cfg}, dashboard.SiteOptions{OutputDir: t.TempDir()})
+		expectError(t, err, `duplicate config name "test"`)
+	})
+}
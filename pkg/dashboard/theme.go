@@ -0,0 +1,176 @@
+package dashboard
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Theme tokens. A renderer colour (or a VisualizationConfig's
+// BackgroundColor) set to one of these follows the widget's active theme,
+// so the same Config reads well in light and dark mode.
+const (
+	ThemeText       = "theme:text"
+	ThemeBackground = "theme:background"
+	ThemeBorder     = "theme:border"
+	ThemeAccent     = "theme:accent"
+	ThemeSubtle     = "theme:subtle"
+	ThemeCanvas     = "theme:canvas"
+	ThemeGrid       = "theme:grid"
+)
+
+// Theme is the palette, fonts and corner radius of a generated widget.
+// Empty fields take LightTheme's value. The widget exposes each field as a
+// --dexetera-* CSS custom property on its root element, so host pages can
+// also restyle a widget from their own stylesheet.
+type Theme struct {
+	// Text colours body text, headings and chart axes.
+	Text string
+	// Background fills panels and buttons.
+	Background string
+	// Border outlines panels and buttons.
+	Border string
+	// Accent colours slider thumbs, slider readouts and default chart fills.
+	Accent string
+	// Subtle is the hover background of buttons and the background of
+	// inline code.
+	Subtle string
+	// Canvas is the canvas background for visualizations using ThemeCanvas.
+	Canvas string
+	// Grid colours chart gridlines.
+	Grid string
+
+	FontFamily     string
+	MonoFontFamily string
+	// Radius is the corner radius of panels and buttons, as a CSS length.
+	Radius string
+}
+
+// LightTheme is the default theme: dark slate text on white panels.
+func LightTheme() Theme {
+	return Theme{
+		Text:           "#2c3e50",
+		Background:     "#ffffff",
+		Border:         "#2c3e50",
+		Accent:         "#3c78d8",
+		Subtle:         "#f4f6f9",
+		Canvas:         "#ffffff",
+		Grid:           "rgba(44,62,80,0.15)",
+		FontFamily:     "system-ui, -apple-system, sans-serif",
+		MonoFontFamily: "ui-monospace, SFMono-Regular, Menlo, monospace",
+		Radius:         "6px",
+	}
+}
+
+// DarkTheme is light text on near-black panels. Set it as
+// WidgetOptions.DarkTheme to follow the reader's system colour scheme.
+func DarkTheme() Theme {
+	t := LightTheme()
+	t.Text = "#e6e9ef"
+	t.Background = "#1e2329"
+	t.Border = "#4a5562"
+	t.Accent = "#6fa1f2"
+	t.Subtle = "#2a313a"
+	t.Canvas = "#161a1f"
+	t.Grid = "rgba(230,233,239,0.15)"
+	return t
+}
+
+// themeTokens are the token names after "theme:", in the order their
+// custom properties are written.
+var themeTokens = []string{"text", "background", "border", "accent", "subtle", "canvas", "grid"}
+
+// withDefaults fills t's empty fields from LightTheme.
+func (t Theme) withDefaults() Theme {
+	d := LightTheme()
+	for _, f := range []struct{ v, def *string }{
+		{&t.Text, &d.Text}, {&t.Background, &d.Background}, {&t.Border, &d.Border},
+		{&t.Accent, &d.Accent}, {&t.Subtle, &d.Subtle}, {&t.Canvas, &d.Canvas},
+		{&t.Grid, &d.Grid}, {&t.FontFamily, &d.FontFamily},
+		{&t.MonoFontFamily, &d.MonoFontFamily}, {&t.Radius, &d.Radius},
+	} {
+		if *f.v == "" {
+			*f.v = *f.def
+		}
+	}
+	return t
+}
+
+// cssVars renders t as CSS custom property declarations.
+func (t Theme) cssVars() string {
+	t = t.withDefaults()
+	colors := []string{t.Text, t.Background, t.Border, t.Accent, t.Subtle, t.Canvas, t.Grid}
+	var b strings.Builder
+	for i, name := range themeTokens {
+		fmt.Fprintf(&b, "--dexetera-%s: %s; ", name, colors[i])
+	}
+	fmt.Fprintf(&b, "--dexetera-font: %s; --dexetera-mono: %s; --dexetera-radius: %s;",
+		t.FontFamily, t.MonoFontFamily, t.Radius)
+	return b.String()
+}
+
+// cssColor turns a theme token into the matching CSS variable reference,
+// passing any other colour through.
+func cssColor(c string) string {
+	if name, ok := strings.CutPrefix(c, "theme:"); ok {
+		return "var(--dexetera-" + name + ")"
+	}
+	return c
+}
+
+// validateThemeRefs rejects theme tokens the widget doesn't define,
+// searching the background and every renderer property.
+func validateThemeRefs(label string, vis *VisualizationConfig) error {
+	if vis == nil {
+		return nil
+	}
+	if err := checkThemeRef(label+": background", vis.BackgroundColor); err != nil {
+		return err
+	}
+	for i, r := range vis.Renderers {
+		if err := checkThemeRef(fmt.Sprintf("%s: renderer %d (%s)", label, i, r.Type), r.Properties); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkThemeRef(label string, v interface{}) error {
+	switch v := v.(type) {
+	case string:
+		if name, ok := strings.CutPrefix(v, "theme:"); ok {
+			for _, t := range themeTokens {
+				if t == name {
+					return nil
+				}
+			}
+			return fmt.Errorf("%s: unknown theme colour %q", label, v)
+		}
+	case map[string]interface{}:
+		for _, k := range slices.Sorted(maps.Keys(v)) {
+			if err := checkThemeRef(label, v[k]); err != nil {
+				return err
+			}
+		}
+	case []map[string]interface{}:
+		for _, m := range v {
+			if err := checkThemeRef(label, m); err != nil {
+				return err
+			}
+		}
+	case []string:
+		for _, s := range v {
+			if err := checkThemeRef(label, s); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for _, e := range v {
+			if err := checkThemeRef(label, e); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package dashboard_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/umbralcalc/dexetera/pkg/dashboard"
+)
+
+func TestGenerateWidget_Theme(t *testing.T) {
+	cfg := baseBuilder().Build()
+	dark := dashboard.DarkTheme()
+	dir := t.TempDir()
+	err := dashboard.GenerateWidget(cfg, dashboard.WidgetOptions{
+		OutputDir: dir,
+		Theme:     &dashboard.Theme{Accent: "#ff8800"},
+		DarkTheme: &dark,
+	})
+	if err != nil {
+		t.Fatalf("GenerateWidget: %v", err)
+	}
+	raw, err := os.ReadFile(filepath.Join(dir, "widget.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	html := string(raw)
+	for _, want := range []string{
+		"--dexetera-accent: #ff8800;",
+		"--dexetera-text: #2c3e50;",
+		"@media (prefers-color-scheme: dark)",
+		"--dexetera-canvas: " + dark.Canvas + ";",
+		"background: var(--dexetera-canvas);",
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("widget.html is missing %q", want)
+		}
+	}
+}
+
+func TestValidate_ThemeColors(t *testing.T) {
+	t.Run("known tokens pass", func(t *testing.T) {
+		vis := dashboard.NewVisualizationBuilder().
+			AddCircle("", 0, 0, 5, &dashboard.ShapeOptions{FillColor: dashboard.ThemeAccent}).
+			Build()
+		if err := baseBuilder().WithVisualization(vis).Build().Validate(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("unknown token is rejected", func(t *testing.T) {
+		vis := dashboard.NewVisualizationBuilder().
+			AddMultiLineChart(0, 0, 100, 100, []dashboard.ChartSeries{
+				{Partition: "p", Color: "theme:purple"},
+			}, nil).
+			Build()
+		expectError(t, baseBuilder().WithVisualization(vis).Build().Validate(), `unknown theme colour "theme:purple"`)
+	})
+}
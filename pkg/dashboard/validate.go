@@ -0,0 +1,366 @@
+package dashboard
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// attributeSafeName matches control names that can appear verbatim in a
+// data-set-<name> attribute.
+var attributeSafeName = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// buttonSafeName matches preset and scenario names, which the widget
+// writes unescaped into a button's data- attribute and selects with a
+// double-quoted attribute selector.
+var buttonSafeName = regexp.MustCompile(`^[^"\\<>&\x00-\x1f]+$`)
+
+// hexColor matches the #rrggbb colours the renderer's colour maps parse.
+var hexColor = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+
+// conditionOps are the comparison operators a Condition may use. The
+// widget script implements the same set.
+var conditionOps = map[string]struct{}{
+	"": {}, "==": {}, "!=": {}, "<": {}, "<=": {}, ">": {}, ">=": {},
+}
+
+// Validate checks the cross-references inside cfg that the builders don't:
+// names that one part of the Config uses to refer to another. It returns
+// the first problem found, phrased so the offending builder call is easy
+// to locate. GenerateWidget calls this before writing any output.
+func (cfg *Config) Validate() error {
+	if err := cfg.validateCanvases(); err != nil {
+		return err
+	}
+	if err := cfg.validateAssets(); err != nil {
+		return err
+	}
+	controls, err := cfg.controlNames()
+	if err != nil {
+		return err
+	}
+	for _, check := range []func(map[string]struct{}) error{
+		cfg.validatePresets,
+		cfg.validateLinks,
+		cfg.validateScenarios,
+		cfg.validateConditions,
+	} {
+		if err := check(controls); err != nil {
+			return err
+		}
+	}
+	if _, err := resolveLayout(cfg); err != nil {
+		return err
+	}
+	return nil
+}
+
+// controlNames collects the names of every value-bearing control (sliders
+// and toggles), rejecting empty and duplicate names across both kinds.
+func (cfg *Config) controlNames() (map[string]struct{}, error) {
+	controls := make(map[string]struct{}, len(cfg.Sliders)+len(cfg.Toggles))
+	add := func(kind, name, label string) error {
+		if name == "" {
+			return fmt.Errorf("%s %q has no name", kind, label)
+		}
+		if strings.HasPrefix(name, "@") {
+			return fmt.Errorf("%s name %q may not start with @ (reserved for layout items)", kind, name)
+		}
+		if _, dup := controls[name]; dup {
+			return fmt.Errorf("duplicate control name %q", name)
+		}
+		controls[name] = struct{}{}
+		return nil
+	}
+	for _, s := range cfg.Sliders {
+		if err := add("slider", s.Name, s.Label); err != nil {
+			return nil, err
+		}
+	}
+	for _, t := range cfg.Toggles {
+		if err := add("toggle", t.Name, t.Label); err != nil {
+			return nil, err
+		}
+	}
+	return controls, nil
+}
+
+func (cfg *Config) validateCanvases() error {
+	if cfg.VisualizationConfig == nil && len(cfg.Canvases) == 0 {
+		return fmt.Errorf("config %q has no visualization", cfg.Name)
+	}
+	if err := validateVisualization("primary canvas", cfg.VisualizationConfig); err != nil {
+		return err
+	}
+	names := make(map[string]struct{}, len(cfg.Canvases))
+	for _, c := range cfg.Canvases {
+		if !attributeSafeName.MatchString(c.Name) {
+			return fmt.Errorf("canvas name %q must be non-empty and use only letters, digits, _ and -", c.Name)
+		}
+		if _, dup := names[c.Name]; dup {
+			return fmt.Errorf("duplicate canvas name %q", c.Name)
+		}
+		names[c.Name] = struct{}{}
+		if c.Visualization == nil {
+			return fmt.Errorf("canvas %q has no visualization", c.Name)
+		}
+		if err := validateVisualization(fmt.Sprintf("canvas %q", c.Name), c.Visualization); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateVisualization checks one canvas's world coordinates, theme
+// colours, renderer properties and property bindings.
+func validateVisualization(label string, vis *VisualizationConfig) error {
+	if err := validateWorld(label, vis); err != nil {
+		return err
+	}
+	if err := validateThemeRefs(label, vis); err != nil {
+		return err
+	}
+	if err := validateRenderers(label, vis); err != nil {
+		return err
+	}
+	return validateBindings(label, vis)
+}
+
+// validateWorld rejects world coordinate systems with an empty or
+// inverted range, which would map every shape to the same pixel.
+func validateWorld(label string, vis *VisualizationConfig) error {
+	if vis == nil || vis.World == nil {
+		return nil
+	}
+	w := vis.World
+	if !(w.MaxX > w.MinX) || !(w.MaxY > w.MinY) {
+		return fmt.Errorf("%s: world range [%g, %g] x [%g, %g] must have Max above Min on both axes",
+			label, w.MinX, w.MaxX, w.MinY, w.MaxY)
+	}
+	return nil
+}
+
+// validateRenderers rejects renderer properties the runtime would
+// silently misdraw: categorical colours it can't parse, and vector field
+// boxes with an empty range, which it would divide by.
+func validateRenderers(label string, vis *VisualizationConfig) error {
+	if vis == nil {
+		return nil
+	}
+	for i, r := range vis.Renderers {
+		if r.Type == "vectorField" {
+			minX, _ := r.Properties["minX"].(float64)
+			maxX, _ := r.Properties["maxX"].(float64)
+			minY, _ := r.Properties["minY"].(float64)
+			maxY, _ := r.Properties["maxY"].(float64)
+			if minX == maxX || minY == maxY {
+				return fmt.Errorf("%s: renderer %d (vectorField) range [%g, %g] x [%g, %g] must be non-empty on both axes",
+					label, i, minX, maxX, minY, maxY)
+			}
+		}
+		categories, _ := r.Properties["categories"].([]string)
+		for _, c := range categories {
+			if !hexColor.MatchString(c) {
+				return fmt.Errorf("%s: renderer %d (%s) category colour %q must be #rrggbb", label, i, r.Type, c)
+			}
+		}
+	}
+	return nil
+}
+
+// validateBindings rejects property bindings that could never read a
+// state value: bound before any renderer, no property name, no
+// partition, or a negative index.
+func validateBindings(label string, vis *VisualizationConfig) error {
+	if vis == nil {
+		return nil
+	}
+	if len(vis.earlyBinds) > 0 {
+		return fmt.Errorf("%s: Bind(%q) was called before any renderer was added", label, vis.earlyBinds[0])
+	}
+	for i, r := range vis.Renderers {
+		for _, prop := range slices.Sorted(maps.Keys(r.Bindings)) {
+			ref := r.Bindings[prop]
+			switch {
+			case prop == "":
+				return fmt.Errorf("%s: renderer %d (%s) binds an unnamed property", label, i, r.Type)
+			case ref.Partition == "" && r.PartitionName == "":
+				return fmt.Errorf("%s: renderer %d (%s) binds %q without a partition", label, i, r.Type, prop)
+			case ref.Index < 0:
+				return fmt.Errorf("%s: renderer %d (%s) binds %q to negative index %d", label, i, r.Type, prop, ref.Index)
+			}
+		}
+	}
+	return nil
+}
+
+func (cfg *Config) validatePresets(controls map[string]struct{}) error {
+	presets := make(map[string]struct{}, len(cfg.Presets))
+	for _, p := range cfg.Presets {
+		if p.Name == "" {
+			return fmt.Errorf("preset has no name")
+		}
+		if !buttonSafeName.MatchString(p.Name) {
+			return fmt.Errorf("preset name %q may not contain quotes, backslashes, <, >, & or control characters", p.Name)
+		}
+		if _, dup := presets[p.Name]; dup {
+			return fmt.Errorf("duplicate preset name %q", p.Name)
+		}
+		presets[p.Name] = struct{}{}
+		for _, name := range slices.Sorted(maps.Keys(p.Values)) {
+			if _, ok := controls[name]; !ok {
+				return fmt.Errorf("preset %q: unknown control %q", p.Name, name)
+			}
+		}
+	}
+	return nil
+}
+
+func (cfg *Config) validateLinks(controls map[string]struct{}) error {
+	if len(cfg.Links) == 0 {
+		return nil
+	}
+	lowered := make(map[string]string, len(controls))
+	for _, name := range slices.Sorted(maps.Keys(controls)) {
+		key := strings.ToLower(name)
+		if other, clash := lowered[key]; clash {
+			return fmt.Errorf(
+				"controls %q and %q differ only in case, which control links cannot distinguish",
+				other, name)
+		}
+		lowered[key] = name
+	}
+	for _, l := range cfg.Links {
+		if len(l.Values) == 0 {
+			return fmt.Errorf("control link %q sets no controls", l.Text)
+		}
+		for _, name := range slices.Sorted(maps.Keys(l.Values)) {
+			if _, ok := controls[name]; !ok {
+				return fmt.Errorf("control link %q: unknown control %q", l.Text, name)
+			}
+			if !attributeSafeName.MatchString(name) {
+				return fmt.Errorf(
+					"control link %q: control name %q is not usable in a data-set-* attribute",
+					l.Text, name)
+			}
+		}
+	}
+	return nil
+}
+
+func (cfg *Config) validateScenarios(controls map[string]struct{}) error {
+	scenarios := make(map[string]struct{}, len(cfg.Scenarios))
+	for _, sc := range cfg.Scenarios {
+		if sc.Name == "" {
+			return fmt.Errorf("scenario has no name")
+		}
+		if !buttonSafeName.MatchString(sc.Name) {
+			return fmt.Errorf("scenario name %q may not contain quotes, backslashes, <, >, & or control characters", sc.Name)
+		}
+		if _, dup := scenarios[sc.Name]; dup {
+			return fmt.Errorf("duplicate scenario name %q", sc.Name)
+		}
+		scenarios[sc.Name] = struct{}{}
+		if len(sc.Events) == 0 {
+			return fmt.Errorf("scenario %q has no events", sc.Name)
+		}
+		for i, e := range sc.Events {
+			if e.At < 0 {
+				return fmt.Errorf("scenario %q: event %d is scheduled before playback starts", sc.Name, i)
+			}
+			for _, name := range slices.Sorted(maps.Keys(e.Values)) {
+				if _, ok := controls[name]; !ok {
+					return fmt.Errorf("scenario %q: event %d: unknown control %q", sc.Name, i, name)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// validateConditions checks every VisibleWhen/EnabledWhen for a known
+// control and operator, then rejects dependency cycles between controls
+// (e.g. slider a visible when toggle b is on, toggle b enabled when a > 0).
+// Only sliders and toggles can be referenced, so only they can form one.
+func (cfg *Config) validateConditions(controls map[string]struct{}) error {
+	dependsOn := make(map[string][]string, len(controls))
+	check := func(kind, name string, conds ...*Condition) error {
+		for _, c := range conds {
+			if c == nil {
+				continue
+			}
+			if _, ok := controls[c.Control]; !ok {
+				return fmt.Errorf("%s %q: condition references unknown control %q", kind, name, c.Control)
+			}
+			if _, ok := conditionOps[c.Op]; !ok {
+				return fmt.Errorf("%s %q: condition has unknown operator %q", kind, name, c.Op)
+			}
+			if kind == "slider" || kind == "toggle" {
+				dependsOn[name] = append(dependsOn[name], c.Control)
+			}
+		}
+		return nil
+	}
+	for _, s := range cfg.Sliders {
+		if err := check("slider", s.Name, s.VisibleWhen, s.EnabledWhen); err != nil {
+			return err
+		}
+	}
+	for _, t := range cfg.Toggles {
+		if err := check("toggle", t.Name, t.VisibleWhen, t.EnabledWhen); err != nil {
+			return err
+		}
+	}
+	for _, p := range cfg.Presets {
+		if err := check("preset", p.Name, p.VisibleWhen, p.EnabledWhen); err != nil {
+			return err
+		}
+	}
+	for _, sc := range cfg.Scenarios {
+		if err := check("scenario", sc.Name, sc.VisibleWhen, sc.EnabledWhen); err != nil {
+			return err
+		}
+	}
+
+	// Depth-first search for a back edge; path holds the current chain so
+	// the error can name the whole cycle.
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make(map[string]int, len(controls))
+	var path []string
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case visiting:
+			start := 0
+			for path[start] != name {
+				start++
+			}
+			cycle := append(append([]string{}, path[start:]...), name)
+			return fmt.Errorf("condition cycle between controls: %s", strings.Join(cycle, " -> "))
+		case done:
+			return nil
+		}
+		state[name] = visiting
+		path = append(path, name)
+		for _, dep := range dependsOn[name] {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = done
+		return nil
+	}
+	for _, name := range slices.Sorted(maps.Keys(controls)) {
+		if err := visit(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
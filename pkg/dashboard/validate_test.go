@@ -0,0 +1,193 @@
+package dashboard_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/umbralcalc/dexetera/pkg/dashboard"
+)
+
+// baseBuilder returns a ConfigBuilder with the minimum a Config needs to
+// pass Validate: a visualization and two sliders named "r" and "K" on one
+// action partition. Tests add the feature under test on top.
+func baseBuilder() *dashboard.ConfigBuilder {
+	return dashboard.NewConfigBuilder("test").
+		WithActionStatePartition("p").
+		WithVisualization(dashboard.NewVisualizationBuilder().Build()).
+		WithSlider(dashboard.Slider{Name: "r", Partition: "p", ValueIndex: 0, Max: 1}).
+		WithSlider(dashboard.Slider{Name: "K", Partition: "p", ValueIndex: 1, Max: 1}).
+		WithInlineDriver(50)
+}
+
+// expectError fails the test unless err is non-nil and mentions want.
+func expectError(t *testing.T, err error, want string) {
+	t.Helper()
+	if err == nil {
+		t.Fatalf("expected error mentioning %q, got nil", want)
+	}
+	if !strings.Contains(err.Error(), want) {
+		t.Fatalf("expected error mentioning %q, got %q", want, err)
+	}
+}
+
+func TestValidate_Presets(t *testing.T) {
+	t.Run("known sliders pass", func(t *testing.T) {
+		cfg := baseBuilder().
+			WithPreset("fast", map[string]float64{"r": 0.5}).
+			WithResettingPreset("restart", map[string]float64{"r": 0.1, "K": 0.2}).
+			Build()
+		if err := cfg.Validate(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("unknown control is rejected", func(t *testing.T) {
+		cfg := baseBuilder().
+			WithPreset("bad", map[string]float64{"q": 1}).
+			Build()
+		expectError(t, cfg.Validate(), `unknown control "q"`)
+	})
+
+	t.Run("duplicate preset name is rejected", func(t *testing.T) {
+		cfg := baseBuilder().
+			WithPreset("a", nil).
+			WithPreset("a", nil).
+			Build()
+		expectError(t, cfg.Validate(), `duplicate preset name "a"`)
+	})
+
+	t.Run("preset name that breaks its selector is rejected", func(t *testing.T) {
+		cfg := baseBuilder().
+			WithPreset(`say "hi"`, nil).
+			Build()
+		expectError(t, cfg.Validate(), "may not contain quotes")
+	})
+}
+
+func TestValidate_ControlLinks(t *testing.T) {
+	t.Run("known sliders pass", func(t *testing.T) {
+		cfg := baseBuilder().
+			WithControlLink("try r = 0.1", map[string]float64{"r": 0.1}).
+			WithResettingControlLink("restart small", map[string]float64{"K": 0.1}).
+			Build()
+		if err := cfg.Validate(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("unknown control is rejected", func(t *testing.T) {
+		cfg := baseBuilder().
+			WithControlLink("bad", map[string]float64{"q": 1}).
+			Build()
+		expectError(t, cfg.Validate(), `unknown control "q"`)
+	})
+
+	t.Run("case-only slider clash is rejected", func(t *testing.T) {
+		cfg := baseBuilder().
+			WithSlider(dashboard.Slider{Name: "k", Partition: "p", ValueIndex: 2}).
+			WithControlLink("try r", map[string]float64{"r": 0.1}).
+			Build()
+		expectError(t, cfg.Validate(), "differ only in case")
+	})
+}
+
+func TestValidate_Scenarios(t *testing.T) {
+	t.Run("known sliders pass", func(t *testing.T) {
+		cfg := baseBuilder().
+			WithScenario(dashboard.Scenario{
+				Name: "demo",
+				Events: []dashboard.ScenarioEvent{
+					{At: 0, Values: map[string]float64{"r": 0.1}},
+					{At: 10, Values: map[string]float64{"K": 0.5}},
+				},
+			}).
+			Build()
+		if err := cfg.Validate(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("unknown control is rejected", func(t *testing.T) {
+		cfg := baseBuilder().
+			WithScenario(dashboard.Scenario{
+				Name:   "bad",
+				Events: []dashboard.ScenarioEvent{{At: 0, Values: map[string]float64{"q": 1}}},
+			}).
+			Build()
+		expectError(t, cfg.Validate(), `unknown control "q"`)
+	})
+
+	t.Run("empty scenario is rejected", func(t *testing.T) {
+		cfg := baseBuilder().WithScenario(dashboard.Scenario{Name: "empty"}).Build()
+		expectError(t, cfg.Validate(), "has no events")
+	})
+
+	t.Run("scenario name that breaks its selector is rejected", func(t *testing.T) {
+		cfg := baseBuilder().
+			WithScenario(dashboard.Scenario{
+				Name:   `a\b`,
+				Events: []dashboard.ScenarioEvent{{At: 0, Values: map[string]float64{"r": 0.1}}},
+			}).
+			Build()
+		expectError(t, cfg.Validate(), "may not contain quotes")
+	})
+}
+
+func TestValidate_Conditions(t *testing.T) {
+	withMode := func() *dashboard.ConfigBuilder {
+		return baseBuilder().
+			WithToggle(dashboard.Toggle{Name: "mode", Partition: "p", ValueIndex: 2})
+	}
+
+	t.Run("references to sliders and toggles pass", func(t *testing.T) {
+		cfg := withMode().
+			WithSlider(dashboard.Slider{
+				Name: "extra", Partition: "p", ValueIndex: 3,
+				VisibleWhen: dashboard.WhenOn("mode"),
+				EnabledWhen: dashboard.When("r", ">", 0.5),
+			}).
+			WithPresetSpec(dashboard.Preset{Name: "p1", VisibleWhen: dashboard.WhenOn("mode")}).
+			Build()
+		if err := cfg.Validate(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("unknown reference is rejected", func(t *testing.T) {
+		cfg := withMode().
+			WithPresetSpec(dashboard.Preset{Name: "p1", EnabledWhen: dashboard.WhenOn("missing")}).
+			Build()
+		expectError(t, cfg.Validate(), `unknown control "missing"`)
+	})
+
+	t.Run("unknown operator is rejected", func(t *testing.T) {
+		cfg := withMode().
+			WithSlider(dashboard.Slider{
+				Name: "extra", Partition: "p", ValueIndex: 3,
+				VisibleWhen: dashboard.When("r", "=>", 0.5),
+			}).
+			Build()
+		expectError(t, cfg.Validate(), `unknown operator "=>"`)
+	})
+
+	t.Run("cycle is rejected", func(t *testing.T) {
+		cfg := baseBuilder().
+			WithToggle(dashboard.Toggle{
+				Name: "a", Partition: "p", ValueIndex: 2,
+				EnabledWhen: dashboard.When("b", ">", 0),
+			}).
+			WithSlider(dashboard.Slider{
+				Name: "b", Partition: "p", ValueIndex: 3,
+				VisibleWhen: dashboard.WhenOn("a"),
+			}).
+			Build()
+		expectError(t, cfg.Validate(), "condition cycle between controls: a -> b -> a")
+	})
+
+	t.Run("toggle and slider names share a namespace", func(t *testing.T) {
+		cfg := baseBuilder().
+			WithToggle(dashboard.Toggle{Name: "r", Partition: "p", ValueIndex: 2}).
+			Build()
+		expectError(t, cfg.Validate(), `duplicate control name "r"`)
+	})
+}
@@ -63,6 +63,23 @@ func NewConfig() *dashboard.Config {
 			Template:  "t = {t} · N = {v}",
 			Decimals:  2,
 		}).
+		// Presets the surrounding prose can point at: a slow start from
+		// the initial population, and a live drop in carrying capacity.
+		WithResettingPreset("slow start", map[string]float64{"r": 0.02, "K": 500}).
+		WithPreset("capacity drop", map[string]float64{"K": 150}).
+		WithControlLink("try r = 0.1", map[string]float64{"r": 0.1}).
+		// A guided run from the initial population: steady growth, a
+		// jump in r, then a drop in K that the population has to track.
+		WithScenario(dashboard.Scenario{
+			Name:   "boom and squeeze",
+			Reset:  true,
+			Length: 450,
+			Events: []dashboard.ScenarioEvent{
+				{At: 0, Values: map[string]float64{"r": 0.05, "K": 500}},
+				{At: 150, Values: map[string]float64{"r": 0.15}},
+				{At: 300, Values: map[string]float64{"K": 150}},
+			},
+		}).
 		WithResetButton().
 		// 50 ms ≈ 20 Hz; the renderer keeps the most recent 100 samples,
 		// so the chart shows roughly the last five seconds of growth.
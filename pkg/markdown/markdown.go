@@ -0,0 +1,309 @@
+// Package markdown expands dexetera widget directives in Markdown files.
+// A directive is a fenced block whose info string starts with "dexetera":
+//
+//	```dexetera name=growth height=300
+//	```
+//
+// and is replaced by the named Config's widget snippet, as a raw HTML
+// block that Markdown renderers pass through untouched. Its attributes
+// are:
+//
+//	name    The Config to embed, looked up in a Registry. Required.
+//	height  Caps each canvas's display height, in CSS pixels.
+//	id      The widget's element ID: a letter, then letters, digits, _
+//	        and -. Defaults to "dexetera-<name>", with "-2", "-3", ...
+//	        appended to repeats within a file.
+package markdown
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/umbralcalc/dexetera/pkg/dashboard"
+)
+
+// Registry holds the Configs directives can name, by Config.Name.
+type Registry map[string]*dashboard.Config
+
+// NewRegistry returns a Registry of configs, rejecting duplicate names.
+func NewRegistry(configs ...*dashboard.Config) (Registry, error) {
+	r := make(Registry, len(configs))
+	for _, cfg := range configs {
+		if _, dup := r[cfg.Name]; dup {
+			return nil, fmt.Errorf("duplicate config name %q", cfg.Name)
+		}
+		r[cfg.Name] = cfg
+	}
+	return r, nil
+}
+
+// Options says where the expanded widgets load their files from.
+type Options struct {
+	// RuntimeBaseURL is the URL the site serves the runtime/ folder at.
+	// Defaults to "/dexetera/runtime/".
+	RuntimeBaseURL string
+
+	// WidgetsBaseURL is the URL under which each Config's wasm
+	// (<name>/main.wasm) and images (<name>/assets/) are served.
+	// Defaults to "/dexetera/widgets/".
+	WidgetsBaseURL string
+
+	// Widget configures every widget. Its WidgetID, URLs and
+	// MaxCanvasHeight are set per directive.
+	Widget dashboard.WidgetOptions
+}
+
+func (o *Options) applyDefaults() {
+	if o.RuntimeBaseURL == "" {
+		o.RuntimeBaseURL = "/dexetera/runtime/"
+	}
+	if o.WidgetsBaseURL == "" {
+		o.WidgetsBaseURL = "/dexetera/widgets/"
+	}
+}
+
+// Expand returns src with every directive replaced by its widget. name
+// labels errors, which give the directive's line.
+func Expand(name string, src []byte, reg Registry, opts Options) ([]byte, error) {
+	return newExpander(reg, opts).expand(name, src)
+}
+
+// expander expands directives against one Registry. It keeps each
+// Config's widget options once prepared, so all of its widgets share one
+// poster run however many directives and files name it.
+type expander struct {
+	reg     Registry
+	opts    Options
+	widgets map[string]dashboard.WidgetOptions
+}
+
+func newExpander(reg Registry, opts Options) *expander {
+	opts.applyDefaults()
+	return &expander{reg: reg, opts: opts, widgets: make(map[string]dashboard.WidgetOptions)}
+}
+
+func (e *expander) expand(name string, src []byte) ([]byte, error) {
+	var out strings.Builder
+	ids := make(map[string]int)
+
+	// fence is the opening run of the fenced block the scanner is inside,
+	// or "" outside one; directive is set while that block is a directive.
+	fence := ""
+	var directive *widgetDirective
+	lines := strings.Split(strings.TrimSuffix(string(src), "\n"), "\n")
+	for i, text := range lines {
+		line := i + 1
+		if fence != "" {
+			if !closesFence(text, fence) {
+				if directive != nil {
+					if strings.TrimSpace(text) != "" {
+						return nil, fmt.Errorf("%s:%d: a dexetera directive takes no content", name, line)
+					}
+					continue
+				}
+				out.WriteString(text + "\n")
+				continue
+			}
+			fence = ""
+			if directive != nil {
+				widget, err := e.render(directive, ids)
+				if err != nil {
+					return nil, fmt.Errorf("%s:%d: %w", name, directive.line, err)
+				}
+				out.WriteString(widget)
+				directive = nil
+				continue
+			}
+			out.WriteString(text + "\n")
+			continue
+		}
+
+		run, info, ok := openFence(text)
+		if !ok {
+			out.WriteString(text + "\n")
+			continue
+		}
+		fence = run
+		if word, attrs, _ := strings.Cut(info, " "); word == "dexetera" {
+			d, err := parseDirective(attrs)
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: %w", name, line, err)
+			}
+			d.line = line
+			directive = d
+			continue
+		}
+		out.WriteString(text + "\n")
+	}
+	if directive != nil {
+		return nil, fmt.Errorf("%s:%d: unclosed dexetera directive", name, directive.line)
+	}
+	return []byte(out.String()), nil
+}
+
+// ExpandDir expands every .md and .markdown file under srcDir into the
+// same relative path under outDir, which may equal srcDir to rewrite the
+// files in place. Other files are left alone, as is outDir when it lies
+// inside srcDir, so earlier output isn't expanded again.
+func ExpandDir(srcDir, outDir string, reg Registry, opts Options) error {
+	e := newExpander(reg, opts)
+	absSrc, err := filepath.Abs(srcDir)
+	if err != nil {
+		return err
+	}
+	absOut, err := filepath.Abs(outDir)
+	if err != nil {
+		return err
+	}
+	return filepath.WalkDir(srcDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if absOut != absSrc {
+				if abs, err := filepath.Abs(p); err == nil && abs == absOut {
+					return fs.SkipDir
+				}
+			}
+			return nil
+		}
+		if ext := filepath.Ext(p); ext != ".md" && ext != ".markdown" {
+			return nil
+		}
+		rel, err := filepath.Rel(srcDir, p)
+		if err != nil {
+			return err
+		}
+		src, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		expanded, err := e.expand(filepath.ToSlash(rel), src)
+		if err != nil {
+			return err
+		}
+		dst := filepath.Join(outDir, rel)
+		if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+			return err
+		}
+		return os.WriteFile(dst, expanded, 0644)
+	})
+}
+
+// openFence reports whether line opens a fenced code block, returning its
+// backtick or tilde run and info string.
+func openFence(line string) (run, info string, ok bool) {
+	trimmed := strings.TrimLeft(line, " ")
+	if len(line)-len(trimmed) > 3 || len(trimmed) < 3 {
+		return "", "", false
+	}
+	c := trimmed[0]
+	if c != '`' && c != '~' {
+		return "", "", false
+	}
+	n := 0
+	for n < len(trimmed) && trimmed[n] == c {
+		n++
+	}
+	if n < 3 {
+		return "", "", false
+	}
+	info = strings.TrimSpace(trimmed[n:])
+	if c == '`' && strings.Contains(info, "`") {
+		return "", "", false
+	}
+	return trimmed[:n], info, true
+}
+
+// closesFence reports whether line closes the block opened by run: the
+// same character, at least as many times, and nothing after.
+func closesFence(line, run string) bool {
+	trimmed := strings.TrimLeft(line, " ")
+	if len(line)-len(trimmed) > 3 {
+		return false
+	}
+	trimmed = strings.TrimRight(trimmed, " \t\r")
+	return len(trimmed) >= len(run) && strings.Trim(trimmed, run[:1]) == ""
+}
+
+// elementID matches the ids a directive may set: they are written
+// unescaped into the widget's id attribute, CSS selectors and script.
+var elementID = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]*$`)
+
+type widgetDirective struct {
+	line   int
+	name   string
+	id     string
+	height int
+}
+
+func parseDirective(attrs string) (*widgetDirective, error) {
+	d := &widgetDirective{}
+	for _, field := range strings.Fields(attrs) {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok || value == "" {
+			return nil, fmt.Errorf("dexetera directive attribute %q must be key=value", field)
+		}
+		value = strings.Trim(value, `"`)
+		switch key {
+		case "name":
+			d.name = value
+		case "id":
+			if !elementID.MatchString(value) {
+				return nil, fmt.Errorf("dexetera directive id %q must start with a letter and use only letters, digits, _ and -", value)
+			}
+			d.id = value
+		case "height":
+			h, err := strconv.Atoi(value)
+			if err != nil || h <= 0 {
+				return nil, fmt.Errorf("dexetera directive height %q must be a positive whole number of pixels", value)
+			}
+			d.height = h
+		default:
+			return nil, fmt.Errorf("unknown dexetera directive attribute %q", key)
+		}
+	}
+	if d.name == "" {
+		return nil, fmt.Errorf("dexetera directive has no name")
+	}
+	return d, nil
+}
+
+// render returns d's widget as a Markdown raw HTML block. ids counts, per
+// Config, the widgets given a default ID so far in the file.
+func (e *expander) render(d *widgetDirective, ids map[string]int) (string, error) {
+	cfg, ok := e.reg[d.name]
+	if !ok {
+		return "", fmt.Errorf("no registered config named %q", d.name)
+	}
+	w, ok := e.widgets[cfg.Name]
+	if !ok {
+		var err error
+		if w, err = dashboard.PreparePoster(cfg, e.opts.Widget); err != nil {
+			return "", err
+		}
+		e.widgets[cfg.Name] = w
+	}
+	w.WidgetID = d.id
+	if w.WidgetID == "" {
+		w.WidgetID = "dexetera-" + cfg.Name
+		if n := ids[cfg.Name]; n > 0 {
+			w.WidgetID += "-" + strconv.Itoa(n+1)
+		}
+		ids[cfg.Name]++
+	}
+	w.RuntimeBaseURL = e.opts.RuntimeBaseURL
+	w.WasmURL = e.opts.WidgetsBaseURL + cfg.Name + "/main.wasm"
+	w.AssetBaseURL = e.opts.WidgetsBaseURL + cfg.Name + "/assets/"
+	w.MaxCanvasHeight = d.height
+	body, err := dashboard.RenderWidget(cfg, w)
+	if err != nil {
+		return "", err
+	}
+	return dashboard.MarkdownHTMLBlock(body), nil
+}
@@ -0,0 +1,153 @@
+package markdown
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/umbralcalc/dexetera/pkg/dashboard"
+	"github.com/umbralcalc/dexetera/pkg/growth"
+	"github.com/umbralcalc/stochadex/pkg/simulator"
+)
+
+func TestExpand(t *testing.T) {
+	reg, err := NewRegistry(growth.NewConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := Options{RuntimeBaseURL: "/rt/", WidgetsBaseURL: "/w/"}
+
+	t.Run("directives become widgets", func(t *testing.T) {
+		src := "# Growth\n\n```dexetera name=growth height=80\n```\n\nText.\n\n~~~dexetera name=growth\n~~~\n"
+		out, err := Expand("post.md", []byte(src), reg, opts)
+		if err != nil {
+			t.Fatalf("Expand: %v", err)
+		}
+		got := string(out)
+		for _, want := range []string{
+			"# Growth\n\n<div id=\"dexetera-growth\" class=\"dexetera-widget\">",
+			`<div id="dexetera-growth-2" class="dexetera-widget">`,
+			"var RENDERER_URL = '/rt/renderer.js';",
+			"var WASM_URL = '/w/growth/main.wasm';",
+			"max-width: 160px;",
+			"\nText.\n",
+		} {
+			if !strings.Contains(got, want) {
+				t.Errorf("expanded markdown is missing %q", want)
+			}
+		}
+		if strings.Contains(got, "```") || strings.Contains(got, "~~~") {
+			t.Errorf("directive fences were left in place")
+		}
+		widget := got[strings.Index(got, "<div"):strings.Index(got, "Text.")]
+		if strings.Contains(strings.TrimSuffix(widget, "\n\n"), "\n\n") {
+			t.Errorf("widget has a blank line, which would end its HTML block")
+		}
+	})
+
+	t.Run("directives inside other code blocks are kept", func(t *testing.T) {
+		src := "````markdown\n```dexetera name=growth\n```\n````\n"
+		out, err := Expand("post.md", []byte(src), reg, opts)
+		if err != nil {
+			t.Fatalf("Expand: %v", err)
+		}
+		if string(out) != src {
+			t.Errorf("got %q, want the source unchanged", out)
+		}
+	})
+
+	t.Run("repeats share one poster run", func(t *testing.T) {
+		cfg := growth.NewConfig()
+		runs := 0
+		cfg.SimulationGenerator = func() *simulator.ConfigGenerator {
+			runs++
+			return growth.BuildGrowthSimulation()
+		}
+		reg, err := NewRegistry(cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		posterOpts := opts
+		posterOpts.Widget.Poster = &dashboard.PosterOptions{Steps: 5}
+		src := "```dexetera name=growth\n```\n\n```dexetera name=growth\n```\n"
+		if _, err := Expand("post.md", []byte(src), reg, posterOpts); err != nil {
+			t.Fatalf("Expand: %v", err)
+		}
+		if runs != 1 {
+			t.Errorf("simulation ran %d times, want 1", runs)
+		}
+	})
+
+	for _, tc := range []struct {
+		name, src, want string
+	}{
+		{"unknown config", "```dexetera name=nope\n```\n", `post.md:1: no registered config named "nope"`},
+		{"missing name", "\n```dexetera height=3\n```\n", "post.md:2: dexetera directive has no name"},
+		{"bad height", "```dexetera name=growth height=tall\n```\n", `height "tall"`},
+		{"unsafe id", "```dexetera name=growth id=a'b\n```\n", `id "a'b" must start with a letter`},
+		{"unknown attribute", "```dexetera name=growth width=3\n```\n", `unknown dexetera directive attribute "width"`},
+		{"content", "```dexetera name=growth\nhello\n```\n", "post.md:2: a dexetera directive takes no content"},
+		{"unclosed", "```dexetera name=growth\n", "post.md:1: unclosed dexetera directive"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Expand("post.md", []byte(tc.src), reg, opts)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("got error %v, want one containing %q", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestExpandDir(t *testing.T) {
+	reg, err := NewRegistry(growth.NewConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, out := t.TempDir(), t.TempDir()
+	if err := os.MkdirAll(filepath.Join(src, "posts"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(src, "posts", "a.md"), []byte("```dexetera name=growth\n```\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(src, "notes.txt"), []byte("```dexetera name=growth\n```\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ExpandDir(src, out, reg, Options{}); err != nil {
+		t.Fatalf("ExpandDir: %v", err)
+	}
+	raw, err := os.ReadFile(filepath.Join(out, "posts", "a.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(raw), "var WASM_URL = '/dexetera/widgets/growth/main.wasm';") {
+		t.Errorf("posts/a.md was not expanded with the default URLs")
+	}
+	if _, err := os.Stat(filepath.Join(out, "notes.txt")); !os.IsNotExist(err) {
+		t.Errorf("non-markdown files should not be written, got %v", err)
+	}
+
+	t.Run("nested out dir is not expanded again", func(t *testing.T) {
+		src := t.TempDir()
+		out := filepath.Join(src, "out")
+		if err := os.WriteFile(filepath.Join(src, "a.md"), []byte("```dexetera name=growth\n```\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		for run := 0; run < 2; run++ {
+			if err := ExpandDir(src, out, reg, Options{}); err != nil {
+				t.Fatalf("ExpandDir run %d: %v", run, err)
+			}
+		}
+		if _, err := os.Stat(filepath.Join(out, "out")); !os.IsNotExist(err) {
+			t.Errorf("second run expanded the first run's output into out/out")
+		}
+		if _, err := os.Stat(filepath.Join(out, "a.md")); err != nil {
+			t.Errorf("a.md was not expanded: %v", err)
+		}
+	})
+
+	if _, err := NewRegistry(growth.NewConfig(), growth.NewConfig()); err == nil {
+		t.Errorf("expected duplicate registry names to be rejected")
+	}
+}
@@ -0,0 +1,138 @@
+package simio
+
+import (
+	"github.com/umbralcalc/dexetera/pkg/dashboard"
+	"github.com/umbralcalc/stochadex/pkg/simulator"
+)
+
+// controlTarget is the (partition, slot) pair a slider or toggle writes
+// to, resolved against the running coordinator's partition indices.
+type controlTarget struct {
+	partitionIndex int
+	valueIndex     int
+}
+
+// ScenarioPlayer plays back a Config's Scenarios against a running
+// coordinator. At most one scenario is active at a time; Play replaces
+// whatever was playing.
+//
+// Apply must be called immediately before every coordinator step. Event
+// times are measured from the first Apply after Play, so a scenario
+// started between steps begins on the next one.
+type ScenarioPlayer struct {
+	scenarios map[string]dashboard.Scenario
+	targets   map[string]controlTarget
+
+	active  string
+	events  []dashboard.ScenarioEvent
+	end     float64
+	byTime  bool
+	started bool
+	origin  float64
+	applied int
+}
+
+// NewScenarioPlayer indexes cfg's scenarios and resolves each control to
+// the action partition it drives. Controls on partitions absent from
+// actionPartitionIndexByName are silently skipped when events fire, in
+// the same way ApplyActionState skips unknown partition names.
+func NewScenarioPlayer(
+	cfg *dashboard.Config,
+	actionPartitionIndexByName map[string]int,
+) *ScenarioPlayer {
+	p := &ScenarioPlayer{
+		scenarios: make(map[string]dashboard.Scenario, len(cfg.Scenarios)),
+		targets:   make(map[string]controlTarget, len(cfg.Sliders)+len(cfg.Toggles)),
+	}
+	for _, sc := range cfg.Scenarios {
+		p.scenarios[sc.Name] = sc
+	}
+	for _, s := range cfg.Sliders {
+		if index, ok := actionPartitionIndexByName[s.Partition]; ok {
+			p.targets[s.Name] = controlTarget{partitionIndex: index, valueIndex: s.ValueIndex}
+		}
+	}
+	for _, t := range cfg.Toggles {
+		if index, ok := actionPartitionIndexByName[t.Partition]; ok {
+			p.targets[t.Name] = controlTarget{partitionIndex: index, valueIndex: t.ValueIndex}
+		}
+	}
+	return p
+}
+
+// Play starts the named scenario from its first event, returning false
+// (and leaving the current playback untouched) if no such scenario exists
+// or it has no events.
+func (p *ScenarioPlayer) Play(name string) bool {
+	sc, ok := p.scenarios[name]
+	if !ok || len(sc.Events) == 0 {
+		return false
+	}
+	p.active = name
+	p.events = sc.SortedEvents()
+	p.end = sc.Length
+	if last := p.events[len(p.events)-1].At; last > p.end {
+		p.end = last
+	}
+	p.byTime = sc.ByTime
+	p.started = false
+	p.applied = 0
+	return true
+}
+
+// Stop ends playback. Action values already applied stay in place.
+func (p *ScenarioPlayer) Stop() {
+	p.active = ""
+	p.events = nil
+}
+
+// Active returns the name of the playing scenario, or "" if none is.
+func (p *ScenarioPlayer) Active() string {
+	return p.active
+}
+
+// Applied returns how many of the playing scenario's events (in
+// Scenario.SortedEvents order) have fired so far.
+func (p *ScenarioPlayer) Applied() int {
+	return p.applied
+}
+
+// Apply fires every event that has come due by the upcoming step, writing
+// each event's control values into the `action_state_values` param of the
+// relevant partitions, then ends playback if the scenario has run its
+// course. Slots not named by an event keep their current values.
+func (p *ScenarioPlayer) Apply(coordinator *simulator.PartitionCoordinator) {
+	if p.active == "" {
+		return
+	}
+	timesteps := coordinator.Shared.TimestepsHistory
+	now := float64(timesteps.CurrentStepNumber)
+	if p.byTime {
+		now = timesteps.Values.AtVec(0)
+	}
+	if !p.started {
+		p.started = true
+		p.origin = now
+	}
+	elapsed := now - p.origin
+
+	for p.applied < len(p.events) && p.events[p.applied].At <= elapsed {
+		for name, value := range p.events[p.applied].Values {
+			target, ok := p.targets[name]
+			if !ok {
+				continue
+			}
+			params := &coordinator.Iterators[target.partitionIndex].Params
+			values, _ := params.GetCopyOk("action_state_values")
+			for len(values) <= target.valueIndex {
+				values = append(values, 0)
+			}
+			values[target.valueIndex] = value
+			params.Set("action_state_values", values)
+		}
+		p.applied++
+	}
+	if p.applied == len(p.events) && elapsed >= p.end {
+		p.Stop()
+	}
+}
@@ -0,0 +1,101 @@
+package simio_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/umbralcalc/dexetera/pkg/dashboard"
+	"github.com/umbralcalc/dexetera/pkg/simio"
+)
+
+// scenarioConfig declares two sliders on "alpha" (slots 0 and 1) plus the
+// given scenario, matching the partitions buildCoordinator creates.
+func scenarioConfig(sc dashboard.Scenario) *dashboard.Config {
+	return dashboard.NewConfigBuilder("scenario").
+		WithActionStatePartition("alpha").
+		WithSlider(dashboard.Slider{Name: "a0", Partition: "alpha", ValueIndex: 0}).
+		WithSlider(dashboard.Slider{Name: "a1", Partition: "alpha", ValueIndex: 1}).
+		WithScenario(sc).
+		Build()
+}
+
+func TestScenarioPlayer_StepSchedule(t *testing.T) {
+	coord, _, byName := buildCoordinator(t)
+	player := simio.NewScenarioPlayer(scenarioConfig(dashboard.Scenario{
+		Name: "demo",
+		// Declared out of order on purpose: playback must sort by At.
+		Events: []dashboard.ScenarioEvent{
+			{At: 2, Values: map[string]float64{"a1": 5.0}},
+			{At: 0, Values: map[string]float64{"a0": 1.0, "a1": 2.0}},
+		},
+	}), byName)
+
+	if !player.Play("demo") {
+		t.Fatal("Play(demo) returned false")
+	}
+	var wg sync.WaitGroup
+	expected := [][]float64{{1.0, 2.0}, {1.0, 2.0}, {1.0, 5.0}}
+	for step, want := range expected {
+		player.Apply(coord)
+		got := coord.Iterators[byName["alpha"]].Params.Get("action_state_values")
+		if len(got) != 2 || got[0] != want[0] || got[1] != want[1] {
+			t.Errorf("step %d: expected %v, got %v", step, want, got)
+		}
+		coord.Step(&wg)
+	}
+	if player.Active() != "" {
+		t.Errorf("expected playback to end after the last event, still playing %q", player.Active())
+	}
+	if got := coord.Iterators[byName["beta"]].Params.Get("action_state_values"); got[0] != 0.0 {
+		t.Errorf("beta: scenario leaked onto a partition it doesn't drive (got %v)", got)
+	}
+}
+
+func TestScenarioPlayer_LengthHoldsPlayback(t *testing.T) {
+	coord, _, byName := buildCoordinator(t)
+	player := simio.NewScenarioPlayer(scenarioConfig(dashboard.Scenario{
+		Name:   "hold",
+		ByTime: true,
+		Length: 3,
+		Events: []dashboard.ScenarioEvent{{At: 0, Values: map[string]float64{"a0": 1.0}}},
+	}), byName)
+	player.Play("hold")
+
+	var wg sync.WaitGroup
+	for step := 0; step < 3; step++ {
+		player.Apply(coord)
+		if player.Active() != "hold" {
+			t.Fatalf("step %d: playback ended before Length elapsed", step)
+		}
+		coord.Step(&wg)
+	}
+	player.Apply(coord)
+	if player.Active() != "" {
+		t.Errorf("expected playback to end once Length elapsed")
+	}
+	if player.Applied() != 1 {
+		t.Errorf("expected 1 applied event, got %d", player.Applied())
+	}
+}
+
+func TestScenarioPlayer_UnknownAndStop(t *testing.T) {
+	coord, _, byName := buildCoordinator(t)
+	player := simio.NewScenarioPlayer(scenarioConfig(dashboard.Scenario{
+		Name:   "later",
+		Events: []dashboard.ScenarioEvent{{At: 5, Values: map[string]float64{"a0": 9.0}}},
+	}), byName)
+
+	if player.Play("nonexistent") {
+		t.Error("Play(nonexistent) returned true")
+	}
+	empty := simio.NewScenarioPlayer(scenarioConfig(dashboard.Scenario{Name: "empty"}), byName)
+	if empty.Play("empty") {
+		t.Error("Play(empty) returned true for a scenario with no events")
+	}
+	player.Play("later")
+	player.Stop()
+	player.Apply(coord)
+	if got := coord.Iterators[byName["alpha"]].Params.Get("action_state_values"); got[0] != 0.0 {
+		t.Errorf("stopped scenario still applied values (got %v)", got)
+	}
+}
@@ -94,6 +94,8 @@ func NewOnlyNamesCondition(names []string) *OnlyNamesCondition {
 //	         the relevant partitions' `action_state_values` params before
 //	         the step runs.
 //
+// Any playing scenario is applied after the incoming action state, so
+// scripted values win over manual input on the steps where events fire.
 // The closure then advances the coordinator by one step and returns nil.
 func GenerateStepClosure(
 	wg *sync.WaitGroup,
@@ -101,6 +103,7 @@ func GenerateStepClosure(
 	coordinator *simulator.PartitionCoordinator,
 	actionPartitionIndices []int,
 	actionPartitionIndexByName map[string]int,
+	player *ScenarioPlayer,
 ) func(this js.Value, args []js.Value) interface{} {
 	return func(this js.Value, args []js.Value) interface{} {
 		*callback = args[0]
@@ -118,14 +121,42 @@ func GenerateStepClosure(
 				&actionState,
 			)
 		}
+		player.Apply(coordinator)
 		coordinator.Step(wg)
 		return nil
 	}
 }
 
+// registerScenarioFuncs exposes the scenario player to runtime/worker.js
+// as three JS globals:
+//
+//	playScenario(name)  start the named scenario; returns false if unknown
+//	stopScenario()      end playback, keeping the values applied so far
+//	scenarioStatus()    { name, applied } — the playing scenario ("" if
+//	                    none) and how many of its events have fired
+func registerScenarioFuncs(player *ScenarioPlayer) {
+	js.Global().Set("playScenario", js.FuncOf(
+		func(this js.Value, args []js.Value) interface{} {
+			return player.Play(args[0].String())
+		}))
+	js.Global().Set("stopScenario", js.FuncOf(
+		func(this js.Value, args []js.Value) interface{} {
+			player.Stop()
+			return nil
+		}))
+	js.Global().Set("scenarioStatus", js.FuncOf(
+		func(this js.Value, args []js.Value) interface{} {
+			return map[string]interface{}{
+				"name":    player.Active(),
+				"applied": player.Applied(),
+			}
+		}))
+}
+
 // RegisterStep is the wasm `main` for an example: it builds the stochadex
 // coordinator from cfg, wires the JS output callback in, and registers a
-// `stepSimulation` global on `js.Global()`. It then blocks forever
+// `stepSimulation` global on `js.Global()` (plus the scenario globals
+// described on registerScenarioFuncs). It then blocks forever
 // (`select {}`) so the Go runtime stays alive to service further calls.
 //
 // The two index structures it builds — actionPartitionIndices (slice,
@@ -159,14 +190,17 @@ func RegisterStep(cfg *dashboard.Config) {
 	implementations.OutputFunction = &JsCallbackOutputFunction{callback: &callback}
 
 	coordinator := simulator.NewPartitionCoordinator(settings, implementations)
+	player := NewScenarioPlayer(cfg, actionPartitionIndexByName)
 	step := GenerateStepClosure(
 		&wg,
 		&callback,
 		coordinator,
 		actionPartitionIndices,
 		actionPartitionIndexByName,
+		player,
 	)
 
 	js.Global().Set("stepSimulation", js.FuncOf(step))
+	registerScenarioFuncs(player)
 	select {}
 }
@@ -4,9 +4,14 @@
 //   initializeRenderer(canvas, config) — bind to a canvas + visualization config
 //   updateVisualization(partitionState) — feed in one partition's latest state
 //
+// The widget script instead calls self.dexetera.createRenderer(canvas,
+// config, { assetBase, assets }), where assetBase prefixes relative image
+// paths and assets (in a single-file bundle) maps them to data: URLs.
+//
 // `config` shape:
 //   {
 //     canvasWidth, canvasHeight, backgroundColor, updateIntervalMs,
+//     world: null | { minX, maxX, minY, maxY, lockAspect, yUp },
 //     renderers: [
 //       { type, partitionName, properties: {...} },
 //       ...
@@ -17,27 +22,134 @@
 //   { partitionName, state: { values: number[] }, timesteps }
 
 class GenericRenderer {
-    constructor(canvas, config) {
+    constructor(canvas, config, options) {
         this.canvas = canvas;
         this.ctx = canvas.getContext('2d');
         this.config = config;
+        this.assetBase = (options && options.assetBase) || '';
+        this.assets = (options && options.assets) || {};
+        this.images = new Map();
+        // Renderers naming a theme colour are resolved once per theme and
+        // again whenever the reader's colour scheme changes.
+        this.themed = new Set(config.renderers.filter(r => JSON.stringify(r.properties || {}).includes('"theme:')));
+        this.resolved = new Map();
+        this.theme = readTheme(canvas);
+        if (typeof matchMedia === 'function') {
+            matchMedia('(prefers-color-scheme: dark)').addEventListener('change', () => this.refreshTheme());
+        }
+        this.transform = worldTransform(config.world, canvas.width, canvas.height);
         this.state = {};
         this.history = {};
+        this.fullHistory = {};
+        this.heatmaps = new Map();
+        this.colorLUTs = new Map();
+        this.retention = {};
+        config.renderers.forEach(r => {
+            const p = r.properties || {};
+            if (r.type === 'lineChart') this.retain(r.partitionName, p.history);
+            if (r.type === 'multiLineChart') (p.series || []).forEach(s => this.retain(s.partition, p.history, true));
+        });
     }
 
-    update(partitionState) {
-        this.state[partitionState.partitionName] = partitionState.state.values;
+    // retain widens a partition's retention policy to cover one chart's
+    // history window. Every partition keeps at least DEFAULT_HISTORY
+    // samples; charts sharing a partition share its buffers. Samples only
+    // carry the whole state vector when a chart asks for values, as a
+    // large lattice would otherwise be copied on every update.
+    retain(partitionName, history, values) {
+        const keep = this.retentionFor(partitionName);
+        if (values) keep.values = true;
+        if (!history) return;
+        if (history.full) {
+            keep.full = true;
+            keep.maxPoints = Math.max(keep.maxPoints, history.maxPoints || DEFAULT_MAX_POINTS);
+        } else if (history.time > 0) {
+            keep.time = Math.max(keep.time, history.time);
+        } else if (history.samples > 0) {
+            keep.samples = Math.max(keep.samples, history.samples);
+        }
+    }
 
-        if (!this.history[partitionState.partitionName]) {
-            this.history[partitionState.partitionName] = [];
+    retentionFor(partitionName) {
+        if (!this.retention[partitionName]) {
+            this.retention[partitionName] = { samples: DEFAULT_HISTORY, time: 0, full: false, maxPoints: 0, values: false };
         }
-        this.history[partitionState.partitionName].push({
+        return this.retention[partitionName];
+    }
+
+    update(partitionState) {
+        const name = partitionState.partitionName;
+        this.state[name] = partitionState.state.values;
+
+        // `value` feeds the single-series lineChart; `values` keeps the
+        // whole state vector for charts that plot other indices.
+        const keep = this.retentionFor(name);
+        const sample = {
             value: partitionState.state.values[0] || 0,
-            time: partitionState.cumulativeTimesteps || 0
-        });
-        if (this.history[partitionState.partitionName].length > 100) {
-            this.history[partitionState.partitionName].shift();
+            time: partitionState.timesteps || 0
+        };
+        if (keep.values) sample.values = partitionState.state.values.slice();
+        let history = this.history[name];
+        // Time running backwards means the simulation was restarted, so
+        // the old run's samples no longer belong on the same axes.
+        if (!history || (history.length > 0 && sample.time < history[history.length - 1].time)) {
+            history = this.history[name] = [];
+            delete this.fullHistory[name];
+        }
+        history.push(sample);
+
+        // Drop the oldest sample once it is outside both the sample and
+        // the time window, never keeping more than MAX_HISTORY.
+        while (history.length > keep.samples &&
+            (history.length > MAX_HISTORY || !(keep.time > 0) || history[0].time < sample.time - keep.time)) {
+            history.shift();
+        }
+
+        if (keep.full) this.recordFull(name, sample, keep.maxPoints);
+    }
+
+    // recordFull keeps the whole run at a decreasing resolution: every
+    // stride-th sample is stored, and whenever the buffer reaches twice
+    // maxPoints every other point is dropped and the stride doubles, so
+    // the points stay evenly spaced across the run.
+    recordFull(name, sample, maxPoints) {
+        let full = this.fullHistory[name];
+        if (!full) full = this.fullHistory[name] = { points: [], stride: 1, seen: 0 };
+        if (full.seen % full.stride === 0) full.points.push(sample);
+        full.seen++;
+        if (full.points.length >= 2 * maxPoints) {
+            full.points = full.points.filter((_, i) => i % 2 === 0);
+            full.stride *= 2;
+        }
+    }
+
+    // chartHistory returns the samples a chart with the given history
+    // window should plot for a partition, oldest first.
+    chartHistory(partitionName, history) {
+        const recent = this.history[partitionName] || [];
+        if (history && history.full) {
+            const full = this.fullHistory[partitionName];
+            if (!full) return recent;
+            const latest = recent[recent.length - 1];
+            const points = full.points;
+            if (latest && points[points.length - 1] !== latest) return points.concat([latest]);
+            return points;
+        }
+        if (history && history.time > 0) {
+            if (recent.length === 0) return recent;
+            const cutoff = recent[recent.length - 1].time - history.time;
+            return recent.filter(pt => pt.time >= cutoff);
         }
+        return recent.slice(-((history && history.samples) || DEFAULT_HISTORY));
+    }
+
+    // refreshTheme re-reads the theme and drops everything drawn from the
+    // old one.
+    refreshTheme() {
+        this.theme = readTheme(this.canvas);
+        this.resolved.clear();
+        this.colorLUTs.clear();
+        this.heatmaps.clear();
     }
 
     render() {
@@ -47,9 +159,21 @@ class GenericRenderer {
         });
     }
 
-    renderElement(renderer) {
+    // renderElement draws one entry of config.renderers. Theming and
+    // bindings draw from a per-frame copy, so the heatmap and colour
+    // caches are keyed by the config entry itself.
+    renderElement(key) {
+        let renderer = key;
         const state = this.state[renderer.partitionName];
         if (!state && renderer.partitionName !== '') return;
+        if (this.themed.has(key)) {
+            renderer = this.resolved.get(key);
+            if (!renderer) {
+                renderer = Object.assign({}, key, { properties: resolveTheme(key.properties, this.theme) });
+                this.resolved.set(key, renderer);
+            }
+        }
+        if (renderer.bindings) renderer = this.bind(renderer);
 
         switch (renderer.type) {
             case 'text':         this.renderText(renderer, state); break;
@@ -59,6 +183,12 @@ class GenericRenderer {
             case 'line':         this.renderLine(renderer, state); break;
             case 'barChart':     this.renderBarChart(renderer, state); break;
             case 'lineChart':    this.renderLineChart(renderer, state); break;
+            case 'multiLineChart': this.renderMultiLineChart(renderer); break;
+            case 'histogram':    this.renderHistogram(renderer, state); break;
+            case 'heatmap':      this.renderHeatmap(renderer, state, key); break;
+            case 'scatter':      this.renderScatter(renderer, state, key); break;
+            case 'network':      this.renderNetwork(renderer, state || [], key); break;
+            case 'vectorField':  this.renderVectorField(renderer, state || []); break;
             case 'progressBar':  this.renderProgressBar(renderer, state); break;
             case 'image':        this.renderImage(renderer, state); break;
             case 'playerSet':
@@ -66,6 +196,44 @@ class GenericRenderer {
         }
     }
 
+    // bind returns a copy of renderer whose bound properties are replaced
+    // by state[index] * scale + offset (or a template filled with it).
+    // Bindings whose state hasn't arrived keep the static value.
+    bind(renderer) {
+        const properties = Object.assign({}, renderer.properties);
+        for (const [name, b] of Object.entries(renderer.bindings)) {
+            const state = this.state[b.partition];
+            if (!state || !(b.index < state.length)) continue;
+            const v = state[b.index] * b.scale + b.offset;
+            properties[name] = b.template ? b.template.replace('{value}', v.toFixed(b.decimals || 0)) : v;
+        }
+        return Object.assign({}, renderer, { properties });
+    }
+
+    // toCanvas maps a shape renderer's world position to canvas pixels;
+    // without a world coordinate system it is the identity.
+    toCanvas(x, y) {
+        const t = this.transform;
+        if (!t) return [x, y];
+        return [
+            t.ox + (x - t.minX) * t.sx,
+            t.yUp ? t.oy + (t.maxY - y) * t.sy : t.oy + (y - t.minY) * t.sy,
+        ];
+    }
+
+    // worldSize maps a world width and height to pixels.
+    worldSize(w, h) {
+        const t = this.transform;
+        return t ? [w * t.sx, h * t.sy] : [w, h];
+    }
+
+    // worldLength maps a world length with no direction (a radius) to
+    // pixels, using the smaller scale when the aspect isn't locked.
+    worldLength(l) {
+        const t = this.transform;
+        return t ? l * Math.min(t.sx, t.sy) : l;
+    }
+
     renderText(renderer, state) {
         // Honor caller-supplied color/font/alignment from the
         // properties object. Defaults match the previous hardcoded
@@ -74,7 +242,7 @@ class GenericRenderer {
         // TextOptions emitted by pkg/dashboard's AddText helper.
         const fontSize = renderer.properties.fontSize || 16;
         const fontFamily = renderer.properties.fontFamily || 'Arial';
-        this.ctx.fillStyle = renderer.properties.color || '#ffffff';
+        this.ctx.fillStyle = renderer.properties.color || this.theme.text;
         this.ctx.font = `${fontSize}px ${fontFamily}`;
         this.ctx.textAlign = renderer.properties.textAlign || 'center';
         let text = renderer.properties.text || '{value}';
@@ -82,15 +250,21 @@ class GenericRenderer {
         // an empty partitionName (static labels).
         const v = (state && state.length > 0) ? state[0] : 0;
         text = text.replace('{value}', Math.floor(v || 0));
-        this.ctx.fillText(text,
-            renderer.properties.x || this.canvas.width / 2,
-            renderer.properties.y || this.canvas.height / 2);
+        // Pixel coordinates of 0 mean "centre", as they always have; in
+        // world coordinates 0 is a real position.
+        const [x, y] = this.transform
+            ? this.toCanvas(renderer.properties.x || 0, renderer.properties.y || 0)
+            : [renderer.properties.x || this.canvas.width / 2, renderer.properties.y || this.canvas.height / 2];
+        this.ctx.fillText(text, x, y);
     }
 
     renderCircle(renderer, state) {
-        const x = renderer.properties.x || this.canvas.width / 2;
-        const y = renderer.properties.y || this.canvas.height / 2;
-        const radius = renderer.properties.radius || 10;
+        const [x, y] = this.transform
+            ? this.toCanvas(renderer.properties.x || 0, renderer.properties.y || 0)
+            : [renderer.properties.x || this.canvas.width / 2, renderer.properties.y || this.canvas.height / 2];
+        const radius = this.transform
+            ? this.worldLength(renderer.properties.radius || 0)
+            : renderer.properties.radius || 10;
         this.ctx.beginPath();
         this.ctx.arc(x, y, radius, 0, 2 * Math.PI);
         if (renderer.properties.fillColor) {
@@ -103,16 +277,18 @@ class GenericRenderer {
             this.ctx.stroke();
         }
         if (!renderer.properties.fillColor && !renderer.properties.strokeColor) {
-            this.ctx.fillStyle = renderer.properties.color || '#ffffff';
+            this.ctx.fillStyle = renderer.properties.color || this.theme.text;
             this.ctx.fill();
         }
     }
 
     renderRectangle(renderer, state) {
-        const x = renderer.properties.x || 0;
-        const y = renderer.properties.y || 0;
-        const width = renderer.properties.width || 50;
-        const height = renderer.properties.height || 50;
+        // (x, y) is the top-left corner in world coordinates too, which
+        // with YUp is the corner with the larger y.
+        const [x, y] = this.toCanvas(renderer.properties.x || 0, renderer.properties.y || 0);
+        const [width, height] = this.transform
+            ? this.worldSize(renderer.properties.width || 0, renderer.properties.height || 0)
+            : [renderer.properties.width || 50, renderer.properties.height || 50];
         if (renderer.properties.fillColor) {
             this.ctx.fillStyle = renderer.properties.fillColor;
             this.ctx.fillRect(x, y, width, height);
@@ -123,7 +299,7 @@ class GenericRenderer {
             this.ctx.strokeRect(x, y, width, height);
         }
         if (!renderer.properties.fillColor && !renderer.properties.strokeColor) {
-            this.ctx.fillStyle = renderer.properties.color || '#ffffff';
+            this.ctx.fillStyle = renderer.properties.color || this.theme.text;
             this.ctx.fillRect(x, y, width, height);
         }
     }
@@ -131,7 +307,7 @@ class GenericRenderer {
     renderRectangleSet(renderer, state) {
         const defaultWidth = renderer.properties.defaultWidth || 12;
         const defaultHeight = renderer.properties.defaultHeight || 8;
-        const fill = renderer.properties.fillColor || renderer.properties.color || '#ffffff';
+        const fill = renderer.properties.fillColor || renderer.properties.color || this.theme.text;
         const stroke = renderer.properties.strokeColor;
         const strokeWidth = renderer.properties.strokeWidth || 1;
         const topLeftAnchor = renderer.properties.anchor === 'topLeft';
@@ -146,10 +322,10 @@ class GenericRenderer {
             if (!width || !height || width <= 0 || height <= 0) continue;
             if (!Number.isFinite(x) || !Number.isFinite(y)) continue;
 
-            const drawWidth = Math.abs(width);
-            const drawHeight = Math.abs(height);
-            const left = topLeftAnchor ? x : x - drawWidth / 2;
-            const top = topLeftAnchor ? y : y - drawHeight / 2;
+            const [drawWidth, drawHeight] = this.worldSize(Math.abs(width), Math.abs(height));
+            const [cx, cy] = this.toCanvas(x, y);
+            const left = topLeftAnchor ? cx : cx - drawWidth / 2;
+            const top = topLeftAnchor ? cy : cy - drawHeight / 2;
 
             this.ctx.fillStyle = fill;
             this.ctx.fillRect(left, top, drawWidth, drawHeight);
@@ -162,48 +338,46 @@ class GenericRenderer {
     }
 
     renderLine(renderer, state) {
-        const x1 = renderer.properties.x1 || 0;
-        const y1 = renderer.properties.y1 || 0;
-        const x2 = renderer.properties.x2 || 50;
-        const y2 = renderer.properties.y2 || 50;
+        const p = renderer.properties;
+        const [x1, y1] = this.toCanvas(p.x1 || 0, p.y1 || 0);
+        const [x2, y2] = this.transform
+            ? this.toCanvas(p.x2 || 0, p.y2 || 0)
+            : [p.x2 || 50, p.y2 || 50];
         this.ctx.beginPath();
         this.ctx.moveTo(x1, y1);
         this.ctx.lineTo(x2, y2);
-        this.ctx.strokeStyle = renderer.properties.color || '#ffffff';
+        this.ctx.strokeStyle = renderer.properties.color || this.theme.text;
         this.ctx.lineWidth = renderer.properties.width || 1;
         this.ctx.stroke();
     }
 
     renderBarChart(renderer, state) {
-        const x = renderer.properties.x || 0;
-        const y = renderer.properties.y || 0;
-        const width = renderer.properties.width || 50;
-        const height = renderer.properties.height || 50;
-        const maxValue = renderer.properties.maxValue || 100;
+        const p = renderer.properties;
+        const area = chartArea(p, p.x || 0, p.y || 0, p.width || 50, p.height || 50);
+        const maxValue = p.maxValue || 100;
         const value = state[0] || 0;
         const normalizedValue = Math.min(value / maxValue, 1.0);
 
-        this.ctx.fillStyle = renderer.properties.color || 'rgba(255,255,255,0.3)';
-        this.ctx.fillRect(x, y, width, height);
-        this.ctx.fillStyle = renderer.properties.color || '#4CAF50';
-        this.ctx.fillRect(x, y + height * (1 - normalizedValue), width, height * normalizedValue);
+        this.ctx.fillStyle = this.theme.grid;
+        this.ctx.fillRect(area.x, area.y, area.width, area.height);
+        this.drawAxes(p, area, null, [0, maxValue]);
+        this.ctx.fillStyle = p.color || this.theme.accent;
+        this.ctx.fillRect(area.x, area.y + area.height * (1 - normalizedValue), area.width, area.height * normalizedValue);
 
-        if (renderer.properties.showLabels) {
-            this.ctx.fillStyle = '#ffffff';
+        if (p.showLabels) {
+            this.ctx.fillStyle = this.theme.text;
             this.ctx.font = '12px Arial';
             this.ctx.textAlign = 'center';
-            this.ctx.fillText(Math.floor(value), x + width / 2, y + height / 2);
+            this.ctx.fillText(Math.floor(value), area.x + area.width / 2, area.y + area.height / 2);
         }
     }
 
     renderLineChart(renderer, state) {
-        const history = this.history[renderer.partitionName];
-        if (!history || history.length < 2) return;
+        const p = renderer.properties;
+        const history = this.chartHistory(renderer.partitionName, p.history);
+        if (history.length < 2) return;
 
-        const x = renderer.properties.x || 0;
-        const y = renderer.properties.y || 0;
-        const width = renderer.properties.width || 50;
-        const height = renderer.properties.height || 50;
+        const area = chartArea(p, p.x || 0, p.y || 0, p.width || 50, p.height || 50);
 
         let minVal = Infinity, maxVal = -Infinity;
         history.forEach(point => {
@@ -212,18 +386,330 @@ class GenericRenderer {
         });
         const range = Math.max(maxVal - minVal, 0.1);
 
-        this.ctx.strokeStyle = renderer.properties.color || '#4CAF50';
-        this.ctx.lineWidth = renderer.properties.lineWidth || 2;
+        // xOf maps a sample to its x-axis value: simulation time or its
+        // position in the history window.
+        const byTime = p.xAxisMode === 'time';
+        const xOf = (point, i) => byTime ? point.time : i;
+        const xMin = xOf(history[0], 0);
+        const xMax = xOf(history[history.length - 1], history.length - 1);
+        const xRange = Math.max(xMax - xMin, 1e-9);
+        this.drawAxes(p, area, [xMin, xMax], [minVal, minVal + range]);
+
+        this.ctx.strokeStyle = p.color || this.theme.accent;
+        this.ctx.lineWidth = p.lineWidth || 2;
         this.ctx.beginPath();
         history.forEach((point, i) => {
-            const px = x + (i / (history.length - 1)) * width;
-            const py = y + height - ((point.value - minVal) / range) * height;
+            const px = area.x + ((xOf(point, i) - xMin) / xRange) * area.width;
+            const py = area.y + area.height - ((point.value - minVal) / range) * area.height;
             if (i === 0) this.ctx.moveTo(px, py);
             else this.ctx.lineTo(px, py);
         });
         this.ctx.stroke();
     }
 
+    renderMultiLineChart(renderer) {
+        const p = renderer.properties;
+        const series = p.series || [];
+        const x = p.x || 0;
+        const y = p.y || 0;
+        const area = chartArea(p, x, y, p.width || 200, p.height || 100);
+        const textColor = p.textColor || this.theme.text;
+
+        const lines = series.map(s => this.chartHistory(s.partition, p.history)
+            .map(h => ({ time: h.time, value: h.values[s.index || 0] }))
+            .filter(pt => Number.isFinite(pt.value)));
+
+        let tMin = Infinity, tMax = -Infinity, vMin = Infinity, vMax = -Infinity;
+        lines.forEach(points => points.forEach(pt => {
+            tMin = Math.min(tMin, pt.time);
+            tMax = Math.max(tMax, pt.time);
+            vMin = Math.min(vMin, pt.value);
+            vMax = Math.max(vMax, pt.value);
+        }));
+        if (p.fixedYRange) {
+            vMin = p.minY;
+            vMax = p.maxY;
+        }
+        const hasData = Number.isFinite(tMin) && Number.isFinite(vMin);
+        const vRange = Math.max(vMax - vMin, 0.1);
+        const tRange = Math.max(tMax - tMin, 1e-9);
+
+        if (p.xAxis || p.yAxis) {
+            this.drawAxes(p, area,
+                hasData ? [tMin, tMax] : null,
+                hasData ? [vMin, vMin + vRange] : null);
+        } else {
+            this.ctx.strokeStyle = textColor;
+            this.ctx.lineWidth = 1;
+            this.ctx.beginPath();
+            this.ctx.moveTo(area.x, area.y);
+            this.ctx.lineTo(area.x, area.y + area.height);
+            this.ctx.lineTo(area.x + area.width, area.y + area.height);
+            this.ctx.stroke();
+        }
+
+        if (hasData) {
+            this.ctx.lineWidth = p.lineWidth || 2;
+            lines.forEach((points, i) => {
+                if (points.length < 2) return;
+                this.ctx.strokeStyle = seriesColor(series[i], i);
+                this.ctx.beginPath();
+                points.forEach((pt, j) => {
+                    const px = area.x + ((pt.time - tMin) / tRange) * area.width;
+                    const frac = Math.max(0, Math.min(1, (pt.value - vMin) / vRange));
+                    const py = area.y + area.height - frac * area.height;
+                    if (j === 0) this.ctx.moveTo(px, py);
+                    else this.ctx.lineTo(px, py);
+                });
+                this.ctx.stroke();
+            });
+        }
+
+        if (p.hideLegend) return;
+        this.ctx.font = '12px Arial';
+        this.ctx.textAlign = 'left';
+        this.ctx.textBaseline = 'middle';
+        let row = 0;
+        series.forEach((s, i) => {
+            if (!s.label) return;
+            const ly = area.y + 8 + row * 16;
+            this.ctx.fillStyle = seriesColor(s, i);
+            this.ctx.fillRect(area.x + 8, ly - 5, 10, 10);
+            this.ctx.fillStyle = textColor;
+            this.ctx.fillText(s.label, area.x + 24, ly);
+            row++;
+        });
+        this.ctx.textBaseline = 'alphabetic';
+    }
+
+    // drawAxes draws a chart's configured axes (p.xAxis / p.yAxis) around
+    // its plot area: axis line, ticks labelled across xRange / yRange,
+    // optional gridlines and a title. A null range draws the axis without
+    // ticks, e.g. before any data has arrived.
+    drawAxes(p, area, xRange, yRange) {
+        const ctx = this.ctx;
+        const color = p.textColor || this.theme.text;
+        ctx.save();
+        ctx.font = '11px Arial';
+        ctx.fillStyle = color;
+        ctx.lineWidth = 1;
+
+        const ticks = (axis, range, place) => {
+            const n = axis.tickCount || 0;
+            if (!range || n < 2) return;
+            for (let i = 0; i < n; i++) {
+                const f = i / (n - 1);
+                place(f, formatTick(axis, range[0] + (range[1] - range[0]) * f));
+            }
+        };
+
+        if (p.yAxis) {
+            const axis = p.yAxis;
+            ctx.textAlign = 'right';
+            ctx.textBaseline = 'middle';
+            ticks(axis, yRange, (f, label) => {
+                const py = area.y + area.height - f * area.height;
+                ctx.strokeStyle = axis.gridlines ? this.theme.grid : color;
+                ctx.beginPath();
+                ctx.moveTo(area.x - 4, py);
+                ctx.lineTo(axis.gridlines ? area.x + area.width : area.x, py);
+                ctx.stroke();
+                ctx.fillText(label, area.x - 6, py);
+            });
+            if (axis.title) {
+                ctx.save();
+                ctx.translate(area.x - (axis.tickCount > 1 ? Y_TICK_SPACE : 0) - 8, area.y + area.height / 2);
+                ctx.rotate(-Math.PI / 2);
+                ctx.textAlign = 'center';
+                ctx.fillText(axis.title, 0, 0);
+                ctx.restore();
+            }
+            ctx.strokeStyle = color;
+            ctx.beginPath();
+            ctx.moveTo(area.x, area.y);
+            ctx.lineTo(area.x, area.y + area.height);
+            ctx.stroke();
+        }
+
+        if (p.xAxis) {
+            const axis = p.xAxis;
+            const base = area.y + area.height;
+            ctx.textAlign = 'center';
+            ctx.textBaseline = 'top';
+            ticks(axis, xRange, (f, label) => {
+                const px = area.x + f * area.width;
+                ctx.strokeStyle = axis.gridlines ? this.theme.grid : color;
+                ctx.beginPath();
+                ctx.moveTo(px, base + 4);
+                ctx.lineTo(px, axis.gridlines ? area.y : base);
+                ctx.stroke();
+                ctx.fillText(label, px, base + 5);
+            });
+            if (axis.title) {
+                const ty = base + (axis.tickCount > 1 ? X_TICK_SPACE : 0) + 2;
+                ctx.fillText(axis.title, area.x + area.width / 2, ty);
+            }
+            ctx.strokeStyle = color;
+            ctx.beginPath();
+            ctx.moveTo(area.x, base);
+            ctx.lineTo(area.x + area.width, base);
+            ctx.stroke();
+        }
+        ctx.restore();
+    }
+
+    renderHistogram(renderer, state) {
+        const p = renderer.properties;
+        const area = chartArea(p, p.x || 0, p.y || 0, p.width || 200, p.height || 100);
+        const values = state.filter(v => Number.isFinite(v));
+
+        let edges = p.edges;
+        if (!edges || edges.length < 2) {
+            let lo = p.min, hi = p.max;
+            if (!p.fixedRange) {
+                // Reduce rather than spread: state vectors can be longer
+                // than the engine's argument limit.
+                lo = values.reduce((a, v) => Math.min(a, v), Infinity);
+                hi = values.reduce((a, v) => Math.max(a, v), -Infinity);
+            }
+            if (!Number.isFinite(lo) || !Number.isFinite(hi)) {
+                lo = 0;
+                hi = 1;
+            } else if (hi <= lo) {
+                lo -= 0.5;
+                hi += 0.5;
+            }
+            const n = p.bins || 20;
+            edges = [];
+            for (let i = 0; i <= n; i++) edges.push(lo + (hi - lo) * i / n);
+        }
+        const bins = edges.length - 1;
+        const first = edges[0], last = edges[bins];
+
+        // Each bin is [edge_i, edge_i+1), except the last, which also
+        // takes values equal to its upper edge.
+        const counts = new Array(bins).fill(0);
+        values.forEach(v => {
+            if (v < first || v > last) return;
+            let i = 0;
+            while (i < bins - 1 && v >= edges[i + 1]) i++;
+            counts[i]++;
+        });
+        const heights = counts.map((c, i) => p.normalized
+            ? (values.length > 0 ? c / (values.length * (edges[i + 1] - edges[i])) : 0)
+            : c);
+
+        const reference = p.referencePartition ? (this.state[p.referencePartition] || []) : [];
+        let maxY = p.maxY || heights.concat(reference.filter(v => Number.isFinite(v)))
+            .reduce((a, v) => Math.max(a, v), 0) * 1.05;
+        if (!(maxY > 0)) maxY = 1;
+        this.drawAxes(p, area, [first, last], [0, maxY]);
+
+        const xOf = v => area.x + ((v - first) / (last - first)) * area.width;
+        const yOf = v => area.y + area.height - Math.min(v / maxY, 1) * area.height;
+        this.ctx.fillStyle = p.color || this.theme.accent;
+        heights.forEach((h, i) => {
+            if (h <= 0) return;
+            const x0 = xOf(edges[i]), x1 = xOf(edges[i + 1]);
+            const top = yOf(h);
+            this.ctx.fillRect(x0 + 0.5, top, Math.max(x1 - x0 - 1, 1), area.y + area.height - top);
+        });
+
+        if (reference.length > 1) {
+            this.ctx.strokeStyle = p.referenceColor || '#e06666';
+            this.ctx.lineWidth = 2;
+            this.ctx.beginPath();
+            reference.forEach((v, i) => {
+                const px = xOf(first + (last - first) * i / (reference.length - 1));
+                const py = yOf(Math.max(v, 0));
+                if (i === 0) this.ctx.moveTo(px, py);
+                else this.ctx.lineTo(px, py);
+            });
+            this.ctx.stroke();
+        }
+    }
+
+    // renderHeatmap paints the grid one pixel per cell into an offscreen
+    // canvas through ImageData, then scales that onto the main canvas in a
+    // single drawImage — cheap enough for 200×200 grids every frame.
+    renderHeatmap(renderer, state, key) {
+        const p = renderer.properties;
+        const rows = p.rows || 1;
+        const cols = p.cols || 1;
+        const x = p.x || 0;
+        const y = p.y || 0;
+        const width = p.width || cols;
+        const height = p.height || rows;
+
+        let cache = this.heatmaps.get(key);
+        if (!cache) {
+            const grid = typeof OffscreenCanvas !== 'undefined'
+                ? new OffscreenCanvas(cols, rows)
+                : Object.assign(document.createElement('canvas'), { width: cols, height: rows });
+            const gctx = grid.getContext('2d');
+            cache = { grid, gctx, image: gctx.createImageData(cols, rows), lut: colorMapLUT(p) };
+            this.heatmaps.set(key, cache);
+        }
+
+        const cells = rows * cols;
+        const categorical = p.colorMap === 'categorical';
+        let lo = p.min, hi = p.max;
+        if (!p.fixedRange && !categorical) {
+            lo = Infinity;
+            hi = -Infinity;
+            for (let i = 0; i < cells; i++) {
+                const v = state[i];
+                if (v < lo) lo = v;
+                if (v > hi) hi = v;
+            }
+        }
+        const span = hi > lo ? hi - lo : 1;
+        const lut = cache.lut;
+        const data = cache.image.data;
+        for (let i = 0; i < cells; i++) {
+            const v = state[i];
+            const o = i * 4;
+            if (!Number.isFinite(v)) {
+                data[o + 3] = 0;
+                continue;
+            }
+            let k;
+            if (categorical) {
+                const n = lut.length / 4;
+                k = ((Math.round(v) % n) + n) % n;
+            } else {
+                k = Math.round(Math.max(0, Math.min(1, (v - lo) / span)) * 255);
+            }
+            data[o] = lut[k * 4];
+            data[o + 1] = lut[k * 4 + 1];
+            data[o + 2] = lut[k * 4 + 2];
+            data[o + 3] = 255;
+        }
+        cache.gctx.putImageData(cache.image, 0, 0);
+
+        const smoothing = this.ctx.imageSmoothingEnabled;
+        this.ctx.imageSmoothingEnabled = false;
+        this.ctx.drawImage(cache.grid, x, y, width, height);
+        this.ctx.imageSmoothingEnabled = smoothing;
+
+        if (p.cellOutline) {
+            this.ctx.strokeStyle = p.cellOutline;
+            this.ctx.lineWidth = 1;
+            this.ctx.beginPath();
+            for (let c = 0; c <= cols; c++) {
+                const px = x + (c / cols) * width;
+                this.ctx.moveTo(px, y);
+                this.ctx.lineTo(px, y + height);
+            }
+            for (let r = 0; r <= rows; r++) {
+                const py = y + (r / rows) * height;
+                this.ctx.moveTo(x, py);
+                this.ctx.lineTo(x + width, py);
+            }
+            this.ctx.stroke();
+        }
+    }
+
     renderProgressBar(renderer, state) {
         const x = renderer.properties.x || 0;
         const y = renderer.properties.y || 0;
@@ -233,9 +719,9 @@ class GenericRenderer {
         const value = Math.max(0, Math.min(state[0] || 0, maxValue));
         const normalizedValue = value / maxValue;
 
-        this.ctx.fillStyle = renderer.properties.backgroundColor || 'rgba(255,255,255,0.3)';
+        this.ctx.fillStyle = renderer.properties.backgroundColor || this.theme.grid;
         this.ctx.fillRect(x, y, width, height);
-        this.ctx.fillStyle = renderer.properties.foregroundColor || '#4CAF50';
+        this.ctx.fillStyle = renderer.properties.foregroundColor || this.theme.accent;
         this.ctx.fillRect(x, y, width * normalizedValue, height);
         if (renderer.properties.borderColor) {
             this.ctx.strokeStyle = renderer.properties.borderColor;
@@ -243,34 +729,235 @@ class GenericRenderer {
             this.ctx.strokeRect(x, y, width, height);
         }
         if (renderer.properties.showLabel) {
-            this.ctx.fillStyle = '#ffffff';
+            this.ctx.fillStyle = this.theme.text;
             this.ctx.font = '12px Arial';
             this.ctx.textAlign = 'center';
             this.ctx.fillText(Math.floor(value) + '%', x + width / 2, y + height / 2 + 4);
         }
     }
 
+    // image returns the cached Image for a path, starting the load on
+    // first use. Paths that aren't URLs come from the bundled assets or
+    // are resolved against assetBase.
+    image(path) {
+        let img = this.images.get(path);
+        if (!img && typeof Image !== 'undefined') {
+            img = new Image();
+            img.onerror = () => { img.failed = true; };
+            img.src = /^([a-z][a-z0-9+.-]*:|\/)/i.test(path) ? path : (this.assets[path] || this.assetBase + path);
+            this.images.set(path, img);
+        }
+        return img;
+    }
+
     renderImage(renderer, state) {
-        const imagePath = renderer.properties.imagePath;
-        if (!imagePath) return;
-        const x = renderer.properties.x || 0;
-        const y = renderer.properties.y || 0;
-        this.ctx.fillStyle = 'rgba(255,255,255,0.5)';
-        this.ctx.fillRect(x, y,
-            renderer.properties.width || 32,
-            renderer.properties.height || 32);
+        const p = renderer.properties;
+        if (!p.imagePath) return;
+        const live = p.fromState && state ? state : [];
+        const at = (i, fallback) => (live.length > i ? live[i] : fallback);
+
+        const img = this.image(p.imagePath);
+        const loaded = img && img.complete && img.naturalWidth > 0;
+
+        // Source frame within a sprite sheet, counting along rows.
+        const cols = p.spriteSheetX || 1;
+        const rows = p.spriteSheetY || 1;
+        const fw = loaded ? img.naturalWidth / cols : 32;
+        const fh = loaded ? img.naturalHeight / rows : 32;
+        const n = cols * rows;
+        const frame = ((Math.floor(at(3, p.frame || 0)) % n) + n) % n;
+
+        // Destination size: world units when set, else the frame's natural
+        // pixel size, keeping the aspect ratio when only one side is given.
+        let [w, h] = this.worldSize(p.width || 0, p.height || 0);
+        if (!w && !h) { w = fw; h = fh; }
+        else if (!w) w = h * fw / fh;
+        else if (!h) h = w * fh / fw;
+
+        const [x, y] = this.toCanvas(at(0, p.x || 0), at(1, p.y || 0));
+        const left = p.centerX ? x - w / 2 : x;
+        const top = p.centerY ? y - h / 2 : y;
+
+        this.ctx.save();
+        this.ctx.globalAlpha = p.opacity || 1;
+        const rotation = at(2, p.rotation || 0);
+        if (rotation) {
+            this.ctx.translate(left + w / 2, top + h / 2);
+            this.ctx.rotate(rotation);
+            this.ctx.translate(-(left + w / 2), -(top + h / 2));
+        }
+        if (loaded) {
+            this.ctx.drawImage(img, (frame % cols) * fw, Math.floor(frame / cols) * fh, fw, fh, left, top, w, h);
+        } else if (!img || img.failed) {
+            // A missing image (or no Image support) still marks its place.
+            this.ctx.fillStyle = this.theme.grid;
+            this.ctx.fillRect(left, top, w, h);
+        }
+        this.ctx.restore();
+    }
+
+    renderScatter(renderer, state, key) {
+        const p = renderer.properties;
+        const area = chartArea(p, p.x || 0, p.y || 0, p.width || 200, p.height || 200);
+        const stride = 2 + (p.colorChannel ? 1 : 0) + (p.sizeChannel ? 1 : 0);
+        const colorAt = p.colorChannel ? 2 : -1;
+        const sizeAt = p.sizeChannel ? stride - 1 : -1;
+
+        const xr = p.fixedRange ? [p.minX, p.maxX] : channelRange(state, 0, stride);
+        const yr = p.fixedRange ? [p.minY, p.maxY] : channelRange(state, 1, stride);
+        this.drawAxes(p, area, xr, yr);
+
+        const lut = colorAt >= 0 ? this.colorLUT(renderer, key) : null;
+        const cr = lut && p.colorMap !== 'categorical'
+            ? channelRange(state, colorAt, stride, p.colorMin, p.colorMax) : null;
+        const sr = sizeAt >= 0 ? channelRange(state, sizeAt, stride, p.sizeMin, p.sizeMax) : null;
+        const radius = p.radius || 3;
+        const maxRadius = p.maxRadius || radius * 4;
+
+        this.ctx.lineWidth = 1;
+        this.ctx.strokeStyle = p.strokeColor || '';
+        for (let i = 0; i + 1 < state.length; i += stride) {
+            const fx = (state[i] - xr[0]) / (xr[1] - xr[0]);
+            const fy = (state[i + 1] - yr[0]) / (yr[1] - yr[0]);
+            if (!(fx >= 0 && fx <= 1 && fy >= 0 && fy <= 1)) continue;
+
+            const fill = lut ? lutColor(lut, cr, state[i + colorAt]) : (p.color || this.theme.accent);
+            const r = sr ? radius + (maxRadius - radius) * unitScale(sr, state[i + sizeAt]) : radius;
+
+            this.ctx.beginPath();
+            this.ctx.arc(area.x + fx * area.width, area.y + area.height - fy * area.height, r, 0, 2 * Math.PI);
+            this.ctx.fillStyle = fill;
+            this.ctx.fill();
+            if (p.strokeColor) this.ctx.stroke();
+        }
+    }
+
+    renderNetwork(renderer, state, key) {
+        const p = renderer.properties;
+        const positions = p.positions || [];
+        const edges = p.edges || [];
+        const radius = p.radius || 6;
+        const maxRadius = p.maxRadius || radius * 2;
+        // Inset by the largest radius so edge nodes stay inside the box.
+        const x = (p.x || 0) + maxRadius;
+        const y = (p.y || 0) + maxRadius;
+        const width = Math.max((p.width || 200) - 2 * maxRadius, 1);
+        const height = Math.max((p.height || 200) - 2 * maxRadius, 1);
+        const px = i => x + positions[i][0] * width;
+        const py = i => y + positions[i][1] * height;
+
+        const weights = p.weightPartition ? this.state[p.weightPartition] : null;
+        const wr = weights ? channelRange(weights, 0, 1) : null;
+        const edgeWidth = p.edgeWidth || 1;
+        const maxEdgeWidth = p.maxEdgeWidth || edgeWidth * 4;
+        this.ctx.strokeStyle = p.edgeColor || this.theme.text;
+        edges.forEach((e, i) => {
+            if (!positions[e[0]] || !positions[e[1]]) return;
+            this.ctx.lineWidth = wr && Number.isFinite(weights[i])
+                ? edgeWidth + (maxEdgeWidth - edgeWidth) * unitScale(wr, weights[i])
+                : edgeWidth;
+            this.ctx.beginPath();
+            this.ctx.moveTo(px(e[0]), py(e[0]));
+            this.ctx.lineTo(px(e[1]), py(e[1]));
+            this.ctx.stroke();
+        });
+
+        const stride = (p.colorChannel ? 1 : 0) + (p.sizeChannel ? 1 : 0);
+        const sizeAt = p.sizeChannel ? stride - 1 : -1;
+        const lut = p.colorChannel ? this.colorLUT(renderer, key) : null;
+        const cr = lut && p.colorMap !== 'categorical'
+            ? channelRange(state, 0, stride, p.colorMin, p.colorMax) : null;
+        const sr = sizeAt >= 0 ? channelRange(state, sizeAt, stride, p.sizeMin, p.sizeMax) : null;
+        this.ctx.lineWidth = 1;
+        this.ctx.strokeStyle = p.strokeColor || '';
+        positions.forEach((_, i) => {
+            const base = i * stride;
+            const fill = lut && base < state.length
+                ? lutColor(lut, cr, state[base])
+                : (p.color || this.theme.accent);
+            const r = sr && base + sizeAt < state.length
+                ? radius + (maxRadius - radius) * unitScale(sr, state[base + sizeAt])
+                : radius;
+            this.ctx.beginPath();
+            this.ctx.arc(px(i), py(i), r, 0, 2 * Math.PI);
+            this.ctx.fillStyle = fill;
+            this.ctx.fill();
+            if (p.strokeColor) this.ctx.stroke();
+        });
+    }
+
+    renderVectorField(renderer, state) {
+        const p = renderer.properties;
+        const vectors = p.vectors || state;
+        const every = p.every || 1;
+        const x = p.x || 0;
+        const y = p.y || 0;
+        const width = p.width || 200;
+        const height = p.height || 200;
+        const sx = width / (p.maxX - p.minX);
+        const sy = height / (p.maxY - p.minY);
+        const step = 4 * every;
+        const count = Math.floor(vectors.length / step);
+        if (count === 0) return;
+
+        // Auto scale: the longest arrow spans the typical gap between
+        // neighbouring arrows, estimated from their density in the box.
+        const spacing = 0.9 * Math.sqrt((width * height) / count);
+        let scale = p.scale;
+        if (!scale || p.normalize) {
+            let longest = 0;
+            for (let i = 0; i + 3 < vectors.length; i += step) {
+                longest = Math.max(longest, Math.hypot(vectors[i + 2] * sx, vectors[i + 3] * sy));
+            }
+            scale = longest > 0 ? spacing / longest : 0;
+        }
+        const head = p.headSize || 5;
+
+        this.ctx.strokeStyle = p.color || this.theme.text;
+        this.ctx.lineWidth = p.lineWidth || 1;
+        this.ctx.beginPath();
+        for (let i = 0; i + 3 < vectors.length; i += step) {
+            let dx = vectors[i + 2] * sx;
+            let dy = -vectors[i + 3] * sy;
+            const length = Math.hypot(dx, dy);
+            if (!(length > 0)) continue;
+            const k = p.normalize ? spacing / length : scale;
+            dx *= k;
+            dy *= k;
+            const x0 = x + (vectors[i] - p.minX) * sx;
+            const y0 = y + height - (vectors[i + 1] - p.minY) * sy;
+            const x1 = x0 + dx, y1 = y0 + dy;
+            const angle = Math.atan2(dy, dx);
+            const h = Math.min(head, Math.hypot(dx, dy) / 2);
+            this.ctx.moveTo(x0, y0);
+            this.ctx.lineTo(x1, y1);
+            this.ctx.moveTo(x1 - h * Math.cos(angle - Math.PI / 6), y1 - h * Math.sin(angle - Math.PI / 6));
+            this.ctx.lineTo(x1, y1);
+            this.ctx.lineTo(x1 - h * Math.cos(angle + Math.PI / 6), y1 - h * Math.sin(angle + Math.PI / 6));
+        }
+        this.ctx.stroke();
+    }
+
+    // colorLUT returns (and caches, under the renderer's config entry
+    // key) the colour lookup table for a renderer with a colour channel.
+    colorLUT(renderer, key) {
+        let lut = this.colorLUTs.get(key);
+        if (!lut) {
+            lut = colorMapLUT(renderer.properties);
+            this.colorLUTs.set(key, lut);
+        }
+        return lut;
     }
 
     renderPointSet(renderer, state) {
         const radius = renderer.properties.radius || 8;
-        const fill = renderer.properties.fillColor || renderer.properties.color || '#ffffff';
+        const fill = renderer.properties.fillColor || renderer.properties.color || this.theme.text;
         const stroke = renderer.properties.strokeColor;
         const strokeWidth = renderer.properties.strokeWidth || 1;
 
         for (let i = 0; i < state.length; i += 2) {
-            const x = state[i];
-            const y = state[i + 1];
-            if (typeof x !== 'number' || typeof y !== 'number') continue;
+            if (typeof state[i] !== 'number' || typeof state[i + 1] !== 'number') continue;
+            const [x, y] = this.toCanvas(state[i], state[i + 1]);
 
             this.ctx.beginPath();
             this.ctx.arc(x, y, radius, 0, 2 * Math.PI);
@@ -285,10 +972,175 @@ class GenericRenderer {
     }
 }
 
+// worldTransform precomputes the world-to-canvas mapping for a
+// visualization's world coordinate system, or returns null for plain
+// canvas pixels.
+function worldTransform(world, width, height) {
+    if (!world) return null;
+    let sx = width / (world.maxX - world.minX);
+    let sy = height / (world.maxY - world.minY);
+    let ox = 0, oy = 0;
+    if (world.lockAspect) {
+        const s = Math.min(sx, sy);
+        ox = (width - s * (world.maxX - world.minX)) / 2;
+        oy = (height - s * (world.maxY - world.minY)) / 2;
+        sx = sy = s;
+    }
+    return { minX: world.minX, minY: world.minY, maxY: world.maxY, sx, sy, ox, oy, yUp: !!world.yUp };
+}
+
+// Rolling charts show the last DEFAULT_HISTORY samples unless their
+// history window says otherwise. A time window still keeps at most
+// MAX_HISTORY samples, and full-history charts are downsampled to between
+// DEFAULT_MAX_POINTS and twice that.
+const DEFAULT_HISTORY = 100;
+const MAX_HISTORY = 10000;
+const DEFAULT_MAX_POINTS = 500;
+
+// Fallback line colours for chart series that don't set their own.
+const SERIES_PALETTE = ['#3c78d8', '#e06666', '#6aa84f', '#f1c232', '#8e7cc3', '#e69138'];
+
+function seriesColor(series, i) {
+    return series.color || SERIES_PALETTE[i % SERIES_PALETTE.length];
+}
+
+// Space reserved beside / below a chart's plot area for tick labels and
+// axis titles.
+const Y_TICK_SPACE = 40;
+const X_TICK_SPACE = 16;
+const TITLE_SPACE = 16;
+// THEME_DEFAULTS are LightTheme's colours, used for theme tokens when the
+// canvas isn't inside a widget that defines the --dexetera-* properties.
+const THEME_DEFAULTS = {
+    text: '#2c3e50', background: '#ffffff', border: '#2c3e50', accent: '#3c78d8',
+    subtle: '#f4f6f9', canvas: '#ffffff', grid: 'rgba(44,62,80,0.15)',
+};
+
+// readTheme reads the widget's theme colours from the custom properties
+// in effect on the canvas, which change with prefers-color-scheme.
+function readTheme(canvas) {
+    if (typeof getComputedStyle !== 'function') return THEME_DEFAULTS;
+    const style = getComputedStyle(canvas);
+    const theme = {};
+    for (const name of Object.keys(THEME_DEFAULTS)) {
+        theme[name] = style.getPropertyValue('--dexetera-' + name).trim() || THEME_DEFAULTS[name];
+    }
+    return theme;
+}
+
+// resolveTheme replaces every "theme:<name>" string inside a renderer's
+// properties with the theme's colour.
+function resolveTheme(value, theme) {
+    if (typeof value === 'string') {
+        return value.startsWith('theme:') ? (theme[value.slice(6)] || value) : value;
+    }
+    if (Array.isArray(value)) {
+        return typeof value[0] === 'number' ? value : value.map(v => resolveTheme(v, theme));
+    }
+    if (value && typeof value === 'object') {
+        const out = {};
+        for (const [k, v] of Object.entries(value)) out[k] = resolveTheme(v, theme);
+        return out;
+    }
+    return value;
+}
+
+// chartArea returns the plot rectangle inside a chart's box, leaving room
+// for whichever axis tick labels and titles are configured.
+function chartArea(p, x, y, width, height) {
+    let left = 0, bottom = 0;
+    if (p.yAxis) {
+        if (p.yAxis.tickCount > 1) left += Y_TICK_SPACE;
+        if (p.yAxis.title) left += TITLE_SPACE;
+    }
+    if (p.xAxis) {
+        if (p.xAxis.tickCount > 1) bottom += X_TICK_SPACE;
+        if (p.xAxis.title) bottom += TITLE_SPACE;
+    }
+    return { x: x + left, y: y, width: Math.max(width - left, 1), height: Math.max(height - bottom, 1) };
+}
+
+function formatTick(axis, v) {
+    return (axis.tickFormat || '{value}').replace('{value}', v.toFixed(axis.tickDecimals || 0));
+}
+
+// Anchor colours for the continuous heatmap colour maps, evenly spaced
+// from the low end of the range to the high end.
+const COLOR_MAPS = {
+    viridis: ['#440154', '#3b528b', '#21918c', '#5ec962', '#fde725'],
+    diverging: ['#3b4cc0', '#f7f7f7', '#b40426'],
+};
+
+function parseHex(hex) {
+    const n = parseInt(hex.slice(1), 16);
+    return [(n >> 16) & 255, (n >> 8) & 255, n & 255];
+}
+
+// colorMapLUT returns the RGBA lookup table for a heatmap: 256 entries
+// interpolated along a continuous map, or one entry per category. Only
+// #rrggbb colours are understood.
+function colorMapLUT(p) {
+    if (p.colorMap === 'categorical') {
+        const colors = p.categories || SERIES_PALETTE;
+        const lut = new Uint8ClampedArray(colors.length * 4);
+        colors.forEach((c, i) => {
+            lut.set(parseHex(c), i * 4);
+            lut[i * 4 + 3] = 255;
+        });
+        return lut;
+    }
+    const anchors = (COLOR_MAPS[p.colorMap] || COLOR_MAPS.viridis).map(parseHex);
+    const lut = new Uint8ClampedArray(256 * 4);
+    for (let k = 0; k < 256; k++) {
+        const t = (k / 255) * (anchors.length - 1);
+        const i = Math.min(Math.floor(t), anchors.length - 2);
+        const f = t - i;
+        for (let ch = 0; ch < 3; ch++) {
+            lut[k * 4 + ch] = anchors[i][ch] + (anchors[i + 1][ch] - anchors[i][ch]) * f;
+        }
+        lut[k * 4 + 3] = 255;
+    }
+    return lut;
+}
+
+// channelRange returns [lo, hi] for the values at start, start+stride, …
+// of a tuple array: the fixed bounds if they differ, else the extent of
+// the current data (padded if it is a single value).
+function channelRange(values, start, stride, lo, hi) {
+    if (lo !== hi && lo !== undefined) return [lo, hi];
+    lo = Infinity;
+    hi = -Infinity;
+    for (let i = start; i < values.length; i += stride) {
+        const v = values[i];
+        if (v < lo) lo = v;
+        if (v > hi) hi = v;
+    }
+    if (hi > lo) return [lo, hi];
+    return Number.isFinite(lo) ? [lo - 0.5, lo + 0.5] : [0, 1];
+}
+
+// unitScale maps v from range onto [0, 1], clamping.
+function unitScale(range, v) {
+    return Math.max(0, Math.min(1, (v - range[0]) / (range[1] - range[0])));
+}
+
+// lutColor looks v up in a colour map table: scaled through range for a
+// continuous map, or by rounded value for a categorical one (null range).
+function lutColor(lut, range, v) {
+    let k;
+    if (range) {
+        k = Math.round(unitScale(range, v) * 255);
+    } else {
+        const n = lut.length / 4;
+        k = ((Math.round(v) % n) + n) % n;
+    }
+    return `rgb(${lut[k * 4]},${lut[k * 4 + 1]},${lut[k * 4 + 2]})`;
+}
+
 // Expose the renderer constructor under a single global namespace so each
 // widget on a page can instantiate its own. No module-level singleton —
 // multiple widgets must not share renderer state.
 self.dexetera = self.dexetera || {};
-self.dexetera.createRenderer = function (canvas, config) {
-    return new GenericRenderer(canvas, config);
+self.dexetera.createRenderer = function (canvas, config, options) {
+    return new GenericRenderer(canvas, config, options);
 };
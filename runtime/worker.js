@@ -12,7 +12,9 @@
 // Lifecycle:
 //
 //   page → worker:
-//     { action: 'start', wasmBinary, driver: { kind, options } }
+//     { action: 'start', wasmBinary, wasmGzip?, driver: { kind, options }, scenario? }
+//     { action: 'playScenario', name }
+//     { action: 'stopScenario' }
 //
 //   worker (this file):
 //     1. loadWasm(wasmBinary) → registers `stepSimulation`.
@@ -24,14 +26,31 @@
 //     { type: 'partitionState', data: { partitionName, timesteps, state: {values} } }
 //     { type: 'status', data: <string> }
 //     { type: 'error',  data: <string> }
+//     { type: 'scenario', data: { name, applied } }   (on change only)
 //
 // All driver-specific behaviour (network connections, page-input handling,
 // pacing) lives in the driver. This file knows nothing about either.
+// Scenario playback is driver-independent: the scenario messages go
+// straight to the wasm-side player (see pkg/simio/scenario.go), and a
+// 'start' message carrying `scenario` plays it as soon as wasm is ready.
+//
+// A single-file widget bundle runs this file from a Blob URL with the
+// scripts it would import concatenated ahead of it and listed in
+// self.dexeteraInlined, and passes the wasm as a gzipped data: URL.
+// A copy published by dashboard.PublishAssets starts with
+// self.dexeteraRuntimeFiles, mapping each import to its content-hashed
+// filename.
+
+function importRuntime(name) {
+    if (self.dexeteraInlined && self.dexeteraInlined.indexOf(name) >= 0) return;
+    const files = self.dexeteraRuntimeFiles;
+    self.importScripts((files && files[name]) || name);
+}
 
-self.importScripts('wasm_exec.js');
-self.importScripts('google-protobuf.js');
-self.importScripts('partition_state_pb.js');
-self.importScripts('action_state_pb.js');
+importRuntime('wasm_exec.js');
+importRuntime('google-protobuf.js');
+importRuntime('partition_state_pb.js');
+importRuntime('action_state_pb.js');
 
 let go;
 let wasmReady = false;
@@ -46,6 +65,26 @@ let wasmReady = false;
 function step(actionBytes) {
     if (!wasmReady) return;
     self.stepSimulation(handlePartitionState, actionBytes);
+    reportScenario();
+}
+
+// Last scenario status posted to the page, as "name#applied". The page
+// only hears about playback when it starts, advances, or ends.
+let lastScenarioKey = '#0';
+
+function reportScenario() {
+    if (typeof self.scenarioStatus !== 'function') return;
+    const status = self.scenarioStatus();
+    const key = status.name + '#' + status.applied;
+    if (key === lastScenarioKey) return;
+    lastScenarioKey = key;
+    postToPage({ type: 'scenario', data: { name: status.name, applied: status.applied } });
+}
+
+function playScenario(name) {
+    if (typeof self.playScenario !== 'function' || !self.playScenario(name)) {
+        postToPage({ type: 'error', data: 'unknown scenario: ' + name });
+    }
 }
 
 // Subscribers to every PartitionState the wasm emits. The first subscriber
@@ -98,28 +137,55 @@ function onPageMessage(callback) {
 let driver = null;
 let started = false;
 
+// Page messages that arrive while wasm and the driver are still loading
+// (e.g. the page's first 'setActions') are held here and replayed once
+// the driver has subscribed, rather than being dropped.
+const pendingPageMessages = [];
+
+function handlePageMessage(msg) {
+    if (msg.action === 'playScenario') {
+        playScenario(msg.name);
+        return;
+    }
+    if (msg.action === 'stopScenario') {
+        if (typeof self.stopScenario === 'function') self.stopScenario();
+        return;
+    }
+    for (let i = 0; i < pageMessageSubscribers.length; i++) {
+        pageMessageSubscribers[i](msg);
+    }
+}
+
 self.onmessage = async function (event) {
     const msg = event.data || {};
 
     if (!started && msg.action === 'start') {
         started = true;
-        await loadWasm(msg.wasmBinary);
+        await loadWasm(msg.wasmBinary, msg.wasmGzip);
+        if (msg.scenario) playScenario(msg.scenario);
         loadDriver(msg.driver || { kind: 'websocket', options: {} });
+        pendingPageMessages.splice(0).forEach(handlePageMessage);
         return;
     }
 
-    if (started) {
-        for (let i = 0; i < pageMessageSubscribers.length; i++) {
-            pageMessageSubscribers[i](msg);
-        }
+    if (!started) return;
+    if (!driver) {
+        pendingPageMessages.push(msg);
+        return;
     }
+    handlePageMessage(msg);
 };
 
-async function loadWasm(wasmBinary) {
+async function loadWasm(wasmBinary, gzipped) {
     try {
         go = new Go();
-        const result = await WebAssembly.instantiateStreaming(
-            fetch(wasmBinary), go.importObject);
+        let response = fetch(wasmBinary);
+        if (gzipped) {
+            response = response.then(r => new Response(
+                r.body.pipeThrough(new DecompressionStream('gzip')),
+                { headers: { 'Content-Type': 'application/wasm' } }));
+        }
+        const result = await WebAssembly.instantiateStreaming(response, go.importObject);
         go.run(result.instance);
         wasmReady = true;
     } catch (err) {
@@ -131,7 +197,7 @@ async function loadWasm(wasmBinary) {
 function loadDriver(spec) {
     const kind = spec.kind || 'websocket';
     try {
-        self.importScripts('drivers/' + kind + '.js');
+        importRuntime('drivers/' + kind + '.js');
     } catch (err) {
         postToPage({ type: 'error', data: 'driver load failed: ' + err.message });
         throw err;